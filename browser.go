@@ -2,11 +2,27 @@ package browser
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/go-rod/rod/lib/proto"
+	"io"
+	"log"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,6 +35,7 @@ type Cookie struct {
 	Expires  time.Time
 	HTTPOnly bool
 	Secure   bool
+	SameSite proto.NetworkCookieSameSite
 }
 
 // Browser represents a managed browser instance.
@@ -34,6 +51,101 @@ type Browser struct {
 	timer       *time.Timer
 	ctx         context.Context
 	cancel      context.CancelFunc
+	gpu         bool
+	launcher    *launcher.Launcher
+	mixed       bool
+	label       string
+	logger      *slog.Logger
+	metrics     MetricsCollector
+	incognito   bool
+	cdpTrace    io.Writer
+	dialer      func(ctx context.Context, network, addr string) (net.Conn, error)
+	execPath    string
+	userDataDir string
+	timeout     time.Duration
+	maxPageMB   int
+
+	// name is the name the browser is registered with by GetNamedBrowser, if any.
+	name string
+
+	// reusePolicy decides when GetBrowser shares the browser, sharedKey is the key it's shared with, if any.
+	reusePolicy ReusePolicy
+	sharedKey   string
+
+	// checkedOut is the number of pages currently taken from the pool,
+	// acquiring is the number of GetPage calls waiting for a slot or creating a page.
+	checkedOut atomic.Int32
+	acquiring  atomic.Int32
+
+	// restartAfter is the number of pages served before the browser is restarted, served counts them since launch.
+	restartAfter int
+	served       atomic.Int32
+
+	// reuseInitialPage hands the tab opened by the browser at launch out as the first page,
+	// initialPages holds the tabs opened at launch that haven't been reused or closed yet.
+	reuseInitialPage bool
+	initialPages     []*rod.Page
+
+	// lazyLaunch defers launching the browser process to the first GetPage, see WithLazyLaunch.
+	lazyLaunch bool
+
+	// proxyRotation is the list of proxies assigned to new pages in turn, proxyIndex is the next one to assign.
+	proxyRotation []string
+	proxyIndex    int
+
+	// proxySchemes maps URL schemes to the proxy used for them, see WithProxyScheme.
+	proxySchemes map[string]string
+
+	// proxyCheckURL is loaded through the proxy at launch to check it works, within proxyCheckTimeout.
+	proxyCheckURL     string
+	proxyCheckTimeout time.Duration
+
+	// proxyAuth is the credential answering the challenges of the proxy, see WithProxyAuth.
+	proxyAuth *Credential
+
+	// windowWidth and windowHeight are the size of the browser window, see WithWindowSize.
+	windowWidth  int
+	windowHeight int
+
+	// slowMotion is the delay of rod between input actions, see WithSlowMotion.
+	slowMotion time.Duration
+
+	// devtools opens DevTools for each tab of a non-headless browser, see WithDevtools.
+	devtools bool
+
+	// extraFlags and extraSwitches are the launch flags set by WithExtraLaunchFlags and WithExtraLaunchSwitches.
+	extraFlags    map[string]string
+	extraSwitches []string
+
+	// ipEchoURL is the service OutboundIP asks for the IP of the browser, see WithIPEchoURL.
+	ipEchoURL string
+
+	// maxPageReuses is the number of times a page is put back before it's discarded, see WithMaxPageReuses.
+	maxPageReuses int
+
+	// launched is the launcher of the running browser process, nil for a remote browser.
+	launched *launcher.Launcher
+
+	// remoteURL is the browser connected to instead of launching one, conn is the connection to it, see WithRemoteURL.
+	remoteURL string
+	conn      *cdp.WebSocket
+
+	// reaperInterval is how often the reaper checks the browser is alive, reaperStop stops the running reaper.
+	reaperInterval time.Duration
+	reaperStop     chan struct{}
+
+	// pages tracks the targets of the pages created by the pool.
+	// The value is the number of times the page was put back, for WithMaxPageReuses.
+	pages map[proto.TargetTargetID]int
+
+	// contexts holds the proxy credential of each browser context created by NewContextWithProxy.
+	contexts map[ContextID]*Credential
+	// contextPages tracks the pages created by GetPageInContext until they're put back, with their context.
+	contextPages map[proto.TargetTargetID]ContextID
+
+	// hijacks tracks the request router running on each page.
+	hijacks  map[proto.TargetTargetID]*pageHijack
+	hijackMu sync.Mutex
 }
 
 // Option is a function type for configuring Browser.
@@ -61,12 +173,214 @@ func WithPoolSize(poolSize int) Option {
 }
 
 // WithIdleTimeout sets the idle timeout for the browser.
+// The browser is closed once no page was taken or put back for the timeout, and none is in use.
 func WithIdleTimeout(idleTimeout time.Duration) Option {
 	return func(b *Browser) {
 		b.idleTimeout = idleTimeout
 	}
 }
 
+// WithGPU enables GPU acceleration, which is disabled by default.
+// It removes flag "--disable-gpu" and "--disable-accelerated-2d-canvas" and enables WebGL.
+// In headless mode WebGL is rendered in software through SwiftShader, so it also works on machines without a GPU.
+func WithGPU(enabled bool) Option {
+	return func(b *Browser) {
+		b.gpu = enabled
+	}
+}
+
+// WithWindowSize sets flag "--window-size=width,height", the size of the browser window, also in headless mode.
+// Pages then render at the size of the window instead of emulating the default 1280x800 laptop screen of rod.
+// Unlike WithViewport, which emulates a viewport for a single page, it applies to every page of the browser.
+func WithWindowSize(width, height int) Option {
+	return func(b *Browser) {
+		b.windowWidth = width
+		b.windowHeight = height
+	}
+}
+
+// WithSlowMotion sets the delay rod waits after each input action, such as a key press or a mouse click,
+// 960µs by default. Zero disables the delay for maximum speed, a larger delay helps to follow the actions when debugging.
+func WithSlowMotion(d time.Duration) Option {
+	return func(b *Browser) {
+		b.slowMotion = d
+	}
+}
+
+// WithDevtools sets flag "--auto-open-devtools-for-tabs", so DevTools opens with every tab, e.g. to debug selectors.
+// It only applies with WithHeadless(false), NewBrowser logs a warning and ignores it for a headless browser.
+func WithDevtools(enabled bool) Option {
+	return func(b *Browser) {
+		b.devtools = enabled
+	}
+}
+
+// WithExtraLaunchFlags sets launch flags the package doesn't wrap, e.g. {"lang": "de-DE"} for "--lang=de-DE".
+// They're set after the built-in flags, so they override the defaults with the same name. Leading dashes are ignored.
+// NewBrowser logs a warning for flags that aren't known Chrome switches, which are often typos, but still sets them.
+func WithExtraLaunchFlags(flags map[string]string) Option {
+	return func(b *Browser) {
+		if b.extraFlags == nil {
+			b.extraFlags = make(map[string]string, len(flags))
+		}
+		for name, value := range flags {
+			b.extraFlags[strings.TrimLeft(name, "-")] = value
+		}
+	}
+}
+
+// WithExtraLaunchSwitches is like WithExtraLaunchFlags for flags without a value, e.g. "mute-audio".
+func WithExtraLaunchSwitches(switches ...string) Option {
+	return func(b *Browser) {
+		for _, name := range switches {
+			b.extraSwitches = append(b.extraSwitches, strings.TrimLeft(name, "-"))
+		}
+	}
+}
+
+// WithAllowMixedContent sets flag "--allow-running-insecure-content",
+// so http subresources of https pages are loaded instead of being blocked.
+func WithAllowMixedContent() Option {
+	return func(b *Browser) {
+		b.mixed = true
+	}
+}
+
+// WithLauncher uses the provided launcher to start the browser instead of the built-in flags.
+// The launcher's flags are used as-is, so options that set launch flags such as WithProxy, WithHeadless and WithGPU
+// have no effect, and flags like "--no-sandbox" become the caller's responsibility.
+// The launcher itself is never started, a copy of its flags is launched each time the browser starts.
+// Launchers can't be compared by value, so GetBrowser only reuses a browser for the exact same launcher pointer.
+func WithLauncher(l *launcher.Launcher) Option {
+	return func(b *Browser) {
+		b.launcher = l
+	}
+}
+
+// WithExecutablePath launches the browser binary at path, e.g. a pinned Chromium build,
+// instead of the one found or downloaded by the launcher. NewBrowser returns an error if the path doesn't exist.
+func WithExecutablePath(path string) Option {
+	return func(b *Browser) {
+		b.execPath = path
+	}
+}
+
+// WithUserDataDir keeps the browser profile in dir, so cookies, storage and logins survive restarts.
+// Incognito contexts never write to the profile, so it also disables WithIncognito,
+// pages are created in the default browser context and share the profile's state.
+func WithUserDataDir(dir string) Option {
+	return func(b *Browser) {
+		b.userDataDir = dir
+		b.incognito = false
+	}
+}
+
+// WithDefaultTimeout bounds each call the browser and its pages make to the browser, so they fail instead of hanging forever.
+// A call still running d after it started fails with context.DeadlineExceeded, however long the page has been held,
+// and a shorter timeout set on the page with page.Timeout still applies.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(b *Browser) {
+		b.timeout = d
+	}
+}
+
+// WithMaxPageMemoryMB discards a page put back into the pool if its JavaScript heap uses more than mb megabytes,
+// so a fresh page takes its place instead of a page bloated by a leaky app.
+func WithMaxPageMemoryMB(mb int) Option {
+	return func(b *Browser) {
+		b.maxPageMB = mb
+	}
+}
+
+// WithMaxPageReuses discards a page once it has been put back into the pool n times,
+// so a fresh page takes its place for the next GetPage.
+func WithMaxPageReuses(n int) Option {
+	return func(b *Browser) {
+		b.maxPageReuses = n
+	}
+}
+
+// WithLabel sets a human-readable label for the browser, included in its log events, its Stats and the events of WithMetrics.
+func WithLabel(name string) Option {
+	return func(b *Browser) {
+		b.label = name
+	}
+}
+
+// WithLogger sets the logger used for the lifecycle events and the background errors of the browser,
+// such as a failure to close it when idle, which are discarded by default or with a nil logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *Browser) {
+		if logger == nil {
+			logger = discardLogger()
+		}
+		b.logger = logger
+	}
+}
+
+// WithCDPTrace dumps every CDP request, response and event exchanged with the browser to w, one per line,
+// which helps to debug interception and other low level helpers. The output is verbose.
+func WithCDPTrace(w io.Writer) Option {
+	return func(b *Browser) {
+		b.cdpTrace = w
+	}
+}
+
+// WithControlTransport connects to the DevTools endpoint of the browser through dial instead of a TCP connection,
+// e.g. to reach an endpoint exposed through a proxied unix socket. The dial function gets the host and port
+// of the endpoint as addr.
+func WithControlTransport(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return func(b *Browser) {
+		b.dialer = dial
+	}
+}
+
+// WithRemoteURL connects to a browser already running elsewhere, e.g. in its own container, instead of launching one.
+// The url is the DevTools endpoint of the browser, "ws://host:9222/devtools/browser/<id>" or "http://host:9222".
+// The options that configure the launch, such as WithHeadless, WithProxy or WithLauncher, are ignored.
+// Closing the browser closes the pages of the pool and disconnects, leaving the remote browser running.
+func WithRemoteURL(controlURL string) Option {
+	return func(b *Browser) {
+		b.remoteURL = controlURL
+	}
+}
+
+// WithIncognito creates each page of the pool in its own incognito context, which is the default.
+// When disabled, pages are created in the default browser context and share cookies, storage and cache,
+// which together with WithHeadless(false) shows the automation in a regular window, handy for debugging.
+func WithIncognito(incognito bool) Option {
+	return func(b *Browser) {
+		b.incognito = incognito
+	}
+}
+
+// WithRestartAfter closes and relaunches the browser once GetPage has served pages pages since it was launched,
+// which keeps very long runs from piling up the leaks of the browser process. The restart is done by the next GetPage
+// once every page is back in the pool, so pages in use are never closed, and the instance stays the same.
+func WithRestartAfter(pages int) Option {
+	return func(b *Browser) {
+		b.restartAfter = pages
+	}
+}
+
+// WithReuseInitialPage hands the about:blank tab opened by the browser at launch out as the first page of the pool.
+// The initial tab belongs to the default browser context instead of an incognito one, so it shares its cookies and storage
+// with other pages of the default context. When disabled, which is the default, the initial tab is closed by the first GetPage.
+func WithReuseInitialPage(reuse bool) Option {
+	return func(b *Browser) {
+		b.reuseInitialPage = reuse
+	}
+}
+
+// WithLazyLaunch defers launching the browser to the first GetPage, so NewBrowser and GetBrowser return right away
+// and an instance that never hands out a page never starts a browser process. The idle timer starts with the browser.
+// The options are still validated by NewBrowser, but WithProxyHealthCheck only runs once the browser is launched.
+func WithLazyLaunch(lazy bool) Option {
+	return func(b *Browser) {
+		b.lazyLaunch = lazy
+	}
+}
+
 // PageOption is a function type for configuring rod.Page.
 type PageOption func(*rod.Page)
 
@@ -117,12 +431,34 @@ func WithCookies(cookies ...Cookie) PageOption {
 				Expires:  proto.TimeSinceEpoch(cookie.Expires.Unix()),
 				HTTPOnly: cookie.HTTPOnly,
 				Secure:   cookie.Secure,
+				SameSite: cookie.SameSite,
 			}
 		}
 		page.MustSetCookies(convertedCookies...)
 	}
 }
 
+// seedLocalStorageJS sets the missing items of the localStorage of the origin before the document's scripts run.
+const seedLocalStorageJS = `((origin, items) => {
+	if (location.origin !== origin) return;
+	try {
+		for (const [key, value] of Object.entries(items)) {
+			if (localStorage.getItem(key) === null) localStorage.setItem(key, value);
+		}
+	} catch (e) {}
+})(%s, %s)`
+
+// WithLocalStorage seeds the localStorage of the origin, e.g. "https://example.com", with the items,
+// before the scripts of any document of the origin loaded in the page run.
+// Items already in the storage are left alone, so values changed by the app are kept across navigations.
+func WithLocalStorage(origin string, items map[string]string) PageOption {
+	return func(page *rod.Page) {
+		o, _ := json.Marshal(strings.TrimSuffix(origin, "/"))
+		i, _ := json.Marshal(items)
+		page.MustEvalOnNewDocument(fmt.Sprintf(seedLocalStorageJS, o, i))
+	}
+}
+
 // GetCookies retrieves cookies from the page and returns them as a slice of Cookie.
 func (b *Browser) GetCookies(page *rod.Page) ([]Cookie, error) {
 	cookies, err := page.Cookies([]string{})
@@ -140,6 +476,7 @@ func (b *Browser) GetCookies(page *rod.Page) ([]Cookie, error) {
 			Expires:  time.Unix(int64(c.Expires), 0),
 			HTTPOnly: c.HTTPOnly,
 			Secure:   c.Secure,
+			SameSite: c.SameSite,
 		}
 	}
 
@@ -159,10 +496,19 @@ var (
 // GetBrowser returns a browser instance with the provided options.
 // If a browser with these options already exists, it returns the existing instance.
 // Otherwise, it creates a new browser instance with these options.
+// Browsers are told apart by the options that affect the launched browser or its pool. WithLabel, WithLogger,
+// WithMetrics, WithCDPTrace, WithControlTransport, WithDefaultTimeout, WithLazyLaunch, WithProxyHealthCheck
+// and WithIPEchoURL don't, so browsers that only differ by them are shared, and keep the ones they were created with.
 func GetBrowser(options ...Option) (*Browser, error) {
+	tempBrowser := newDefaultBrowser()
+	for _, option := range options {
+		option(tempBrowser)
+	}
+	key := tempBrowser.key()
+	instances := tempBrowser.reusePolicy.instances()
+
 	mu.RLock()
-	key := generateKey(options...)
-	if browser, ok := browsers[key]; ok {
+	if browser := sharedBrowser(key, instances); browser != nil {
 		mu.RUnlock()
 		return browser, nil
 	}
@@ -172,65 +518,128 @@ func GetBrowser(options ...Option) (*Browser, error) {
 	defer mu.Unlock()
 
 	// Check again in case another goroutine created the browser while we were waiting for the lock.
-	if browser, ok := browsers[key]; ok {
+	if browser := sharedBrowser(key, instances); browser != nil {
 		return browser, nil
 	}
 
+	// Register the new browser in the first free shard
+	shard := 0
+	for ; shard < instances; shard++ {
+		if _, ok := browsers[shardKey(key, shard)]; !ok {
+			break
+		}
+	}
+
 	browser, err := NewBrowser(options...)
 	if err != nil {
 		return nil, err
 	}
-	browsers[key] = browser
+	browser.sharedKey = shardKey(key, shard)
+	browsers[browser.sharedKey] = browser
 
 	return browser, nil
 }
 
+// ActiveBrowsers returns the number of browser instances shared through GetBrowser.
+func ActiveBrowsers() int {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return len(browsers)
+}
+
+// ResetPool closes every browser instance shared through GetBrowser or GetNamedBrowser like CloseAll, and forgets them,
+// so the next GetBrowser starts a fresh browser. Browsers created with NewBrowser are not affected.
+// It's meant to isolate tests from each other, e.g. by calling it from TestMain after m.Run.
+func ResetPool() error {
+	err := CloseAll()
+
+	mu.Lock()
+	browsers = make(map[string]*Browser)
+	mu.Unlock()
+
+	return err
+}
+
+// CloseAll closes every browser instance shared through GetBrowser or GetNamedBrowser, e.g. on shutdown,
+// and returns the errors of the browsers that failed to close.
+func CloseAll() error {
+	// Close the browsers outside the locks, because Close removes the browser from the maps itself
+	mu.Lock()
+	instances := make([]*Browser, 0, len(browsers))
+	for _, browser := range browsers {
+		instances = append(instances, browser)
+	}
+	mu.Unlock()
+
+	namedMu.Lock()
+	for _, browser := range namedBrowsers {
+		instances = append(instances, browser)
+	}
+	namedMu.Unlock()
+
+	var errs []error
+	for _, browser := range instances {
+		if err := browser.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 // NewBrowser creates a new browser instance with the provided options.
 // Headless will be enabled by default.
 // Pool size will be set to 3 by default.
 // Idle timeout will be set to 5 minutes by default.
+// Pages will be created in incognito contexts by default.
 func NewBrowser(options ...Option) (*Browser, error) {
-	b := &Browser{
-		headless:    true,
-		poolSize:    3,
-		idleTimeout: 5 * time.Minute,
-	}
+	b := newDefaultBrowser()
 
 	for _, option := range options {
 		option(b)
 	}
 
-	// Create a new context for the browser instance
-	b.ctx, b.cancel = context.WithCancel(context.Background())
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	if b.devtools && b.headless {
+		b.log().Warn("devtools can't open in headless mode, ignoring WithDevtools")
+	}
+	b.warnUnknownSwitches()
+
+	// Leave the launch to the first GetPage if requested
+	if b.lazyLaunch {
+		return b, nil
+	}
 
 	// Create a new browser instance
-	return createBrowser(b)
+	if err := b.launch(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
 }
 
-// createBrowser creates a new browser instance with the provided options.
-func createBrowser(b *Browser) (*Browser, error) {
-	// Create a rod control url
-	url := launcher.New().
-		Headless(b.headless).
-		Leakless(true).
-		NoSandbox(true).
-		Delete("enable-automation").
-		Set("ignore-certificate-errors").
-		Set("ignore-certificate-errors-spki-list").
-		Set("ignore-ssl-errors").
-		Set("disable-blink-features", "AutomationControlled").
-		Set("disable-setuid-sandbox").
-		Set("disable-gpu").
-		Set("disable-dev-shm-usage").
-		Set("unlimited-storage").
-		Set("disable-accelerated-2d-canvas").
-		Set("full-memory-crash-report")
+// launch starts the browser process of the instance, or connects to its remote browser,
+// and sets up its page pool and idle timer.
+// It's called by GetPage when the browser isn't running, because of WithLazyLaunch or because it was closed,
+// which launches the same instance in place and registers it again under its key and name,
+// unless another instance took its place meanwhile.
+// It must be called with b.mu held, or before the instance is shared.
+func (b *Browser) launch() error {
+	if err := b.validate(); err != nil {
+		return err
+	}
 
-	// Set proxy if provided
-	if b.proxy != "" {
-		url.Proxy(b.proxy)
+	if b.remoteURL != "" {
+		return b.connectRemote()
 	}
 
+	// Create a rod control url
+	url := b.newLauncher()
+
 	// Create a rod browser
 	browser := rod.New()
 
@@ -241,48 +650,339 @@ func createBrowser(b *Browser) (*Browser, error) {
 		}
 	}()
 
-	browser.ControlURL(url.MustLaunch()).
-		SlowMotion(960 * time.Microsecond)
+	controlURL := url.MustLaunch()
+	if b.cdpTrace != nil || b.dialer != nil || b.timeout > 0 {
+		client, _, err := b.newClient(controlURL)
+		if err != nil {
+			url.Kill()
+			return err
+		}
+		browser.Client(b.callTimeout(client))
+	} else {
+		browser.ControlURL(controlURL)
+	}
+	b.configure(browser)
 
 	// Connect to the browser instance
 	err := browser.Connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+		return fmt.Errorf("failed to connect to browser: %w", err)
+	}
+
+	// Keep track of the tabs opened at launch, the first GetPage reuses or closes them
+	initialPages, err := browser.Pages()
+	if err != nil {
+		browser.MustClose()
+		return fmt.Errorf("failed to list initial pages: %w", err)
+	}
+
+	// Make sure the proxy works before handing the browser out
+	if err := b.checkProxy(browser); err != nil {
+		browser.MustClose()
+		return err
+	}
+
+	b.launched = url
+	b.start(browser, initialPages)
+
+	b.log().Info("browser launched", "pid", url.PID())
+	b.metrics.BrowserLaunched(b.label)
+	b.startTimers(browser)
+
+	return nil
+}
+
+// connectRemote connects to the browser of WithRemoteURL instead of launching one.
+// The tabs already open in the remote browser aren't ours, so they're neither reused nor closed.
+func (b *Browser) connectRemote() error {
+	controlURL, err := launcher.ResolveURL(b.remoteURL)
+	if err != nil {
+		return fmt.Errorf("failed to resolve remote browser url: %w", err)
+	}
+
+	client, conn, err := b.newClient(controlURL)
+	if err != nil {
+		return err
+	}
+
+	browser := rod.New().Client(b.callTimeout(client))
+	b.configure(browser)
+
+	if err := browser.Connect(); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to connect to browser: %w", err)
+	}
+
+	if err := b.checkProxy(browser); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	b.launched = nil
+	b.conn = conn
+	b.start(browser, nil)
+
+	b.log().Info("browser connected", "url", b.remoteURL)
+	b.metrics.BrowserLaunched(b.label)
+	b.startTimers(browser)
+
+	return nil
+}
+
+// configure applies the settings shared by launched and remote browsers to the rod browser.
+func (b *Browser) configure(browser *rod.Browser) {
+	if b.slowMotion > 0 {
+		browser.SlowMotion(b.slowMotion)
+	}
+
+	// Let pages follow the window instead of emulating the default device
+	if b.windowWidth > 0 && b.windowHeight > 0 {
+		browser.NoDefaultDevice()
 	}
+}
+
+// start sets up a fresh page pool for the connected browser. It must be called with b.mu held.
+func (b *Browser) start(browser *rod.Browser, initialPages []*rod.Page) {
+	// Create a new context for the browser instance
+	b.ctx, b.cancel = context.WithCancel(context.Background())
 
 	// Create a rod page pool
 	pool := rod.NewPagePool(b.poolSize)
 
 	b.browser = browser
 	b.pool = &pool
+	b.pages = make(map[proto.TargetTargetID]int)
+	b.contextPages = make(map[proto.TargetTargetID]ContextID)
+	b.checkedOut.Store(0)
+	b.served.Store(0)
+	b.initialPages = initialPages
 	b.lastUsed = time.Now()
+}
+
+// startTimers starts the idle timer and the reaper of the connected browser, and registers the instance again
+// if it was relaunched. It must be called with b.mu held.
+func (b *Browser) startTimers(browser *rod.Browser) {
+	// Drop the idle timer of a previous launch, so it doesn't close this one
+	if b.timer != nil {
+		b.timer.Stop()
+	}
 
 	// Set a timer to close the browser instance when idle
 	// func AfterFunc(d Duration, f func()) *Timer
 	// AfterFunc waits for the duration to elapse and then calls f in its own goroutine.
 	// It returns a Timer that can be used to cancel the call using its Stop method.
 	// The returned Timer's C field is not used and will be nil.
-	b.timer = time.AfterFunc(b.idleTimeout, func() {
-		if err := b.Close(); err != nil {
-			fmt.Println("failed to close browser:", err)
+	b.timer = time.AfterFunc(b.idleTimeout, b.closeIdle)
+
+	// Watch the browser process if requested
+	if b.reaperInterval > 0 {
+		b.reaperStop = make(chan struct{})
+		go b.reap(browser, b.reaperStop)
+	}
+
+	b.register()
+}
+
+// validate checks the options of the browser can be launched with.
+func (b *Browser) validate() error {
+	if b.proxyAuth != nil && b.proxyServer() == "" && b.remoteURL == "" {
+		return errors.New("proxy authentication is set without a proxy")
+	}
+
+	if b.proxyCheckURL != "" && b.proxyCheckTimeout <= 0 {
+		return fmt.Errorf("proxy health check timeout must be positive, got %s", b.proxyCheckTimeout)
+	}
+
+	if b.execPath != "" {
+		if _, err := os.Stat(b.execPath); err != nil {
+			return fmt.Errorf("failed to find browser executable: %w", err)
 		}
-	})
+	}
 
-	return b, nil
+	return nil
+}
+
+// register puts a relaunched instance back under the key and name it was shared with, if they're free.
+func (b *Browser) register() {
+	if b.sharedKey != "" {
+		mu.Lock()
+		if _, ok := browsers[b.sharedKey]; !ok {
+			browsers[b.sharedKey] = b
+		}
+		mu.Unlock()
+	}
+
+	if b.name != "" {
+		namedMu.Lock()
+		if _, ok := namedBrowsers[b.name]; !ok {
+			namedBrowsers[b.name] = b
+		}
+		namedMu.Unlock()
+	}
+}
+
+// newClient connects to the browser with a CDP client that goes through the dialer of WithControlTransport
+// and logs its traffic to the writer of WithCDPTrace, if they're set.
+func (b *Browser) newClient(controlURL string) (*cdp.Client, *cdp.WebSocket, error) {
+	ws := &cdp.WebSocket{}
+	if b.dialer != nil {
+		ws.Dialer = dialerFunc(b.dialer)
+	}
+	if err := ws.Connect(context.Background(), controlURL, nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
+
+	client := cdp.New()
+	if b.cdpTrace != nil {
+		client.Logger(log.New(b.cdpTrace, "", log.LstdFlags))
+	}
+
+	return client.Start(ws), ws, nil
+}
+
+// callTimeout bounds the calls of the client by the timeout of WithDefaultTimeout, if it's set.
+func (b *Browser) callTimeout(client rod.CDPClient) rod.CDPClient {
+	if b.timeout <= 0 {
+		return client
+	}
+
+	return &timeoutClient{CDPClient: client, timeout: b.timeout}
+}
+
+// timeoutClient is a CDP client whose calls fail once they've been running for the timeout.
+type timeoutClient struct {
+	rod.CDPClient
+	timeout time.Duration
+}
+
+// Call calls the method with a context that's done after the timeout.
+func (c *timeoutClient) Call(ctx context.Context, sessionID, method string, params interface{}) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return c.CDPClient.Call(ctx, sessionID, method, params)
+}
+
+// dialerFunc adapts a dial function to the dialer of a CDP websocket.
+type dialerFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// DialContext calls the function.
+func (f dialerFunc) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return f(ctx, network, addr)
+}
+
+// newLauncher returns the launcher used to start the browser with the configured flags.
+func (b *Browser) newLauncher() *launcher.Launcher {
+	// A launcher can only be launched once, so launch a copy of the provided one to allow relaunching
+	if b.launcher != nil {
+		l := launcher.New()
+		l.Flags = make(map[flags.Flag][]string, len(b.launcher.Flags))
+		for name, values := range b.launcher.Flags {
+			l.Flags[name] = append([]string(nil), values...)
+		}
+		if b.execPath != "" {
+			l.Bin(b.execPath)
+		}
+		return l
+	}
+
+	url := launcher.New().
+		Headless(b.headless).
+		Leakless(true).
+		NoSandbox(true).
+		Delete("enable-automation").
+		Set("ignore-certificate-errors").
+		Set("ignore-certificate-errors-spki-list").
+		Set("ignore-ssl-errors").
+		Set("disable-blink-features", "AutomationControlled").
+		Set("disable-setuid-sandbox").
+		Set("disable-dev-shm-usage").
+		Set("unlimited-storage").
+		Set("full-memory-crash-report")
+
+	// Use the provided browser binary if set
+	if b.execPath != "" {
+		url.Bin(b.execPath)
+	}
+
+	// Keep the profile in the provided directory if set
+	if b.userDataDir != "" {
+		url.UserDataDir(b.userDataDir)
+	}
+
+	// Enable WebGL if GPU acceleration is requested, otherwise disable the GPU entirely
+	if b.gpu {
+		url.Set("enable-webgl").
+			Set("ignore-gpu-blocklist").
+			Set("use-gl", "angle")
+		if b.headless {
+			url.Set("use-angle", "swiftshader").
+				Set("enable-unsafe-swiftshader")
+		}
+	} else {
+		url.Set("disable-gpu").
+			Set("disable-accelerated-2d-canvas")
+	}
+
+	// Allow http subresources on https pages if requested
+	if b.mixed {
+		url.Set("allow-running-insecure-content")
+	}
+
+	// Open DevTools with every tab if requested, which only works with a window
+	if b.devtools && !b.headless {
+		url.Set("auto-open-devtools-for-tabs")
+	}
+
+	// Set the window size if provided
+	if b.windowWidth > 0 && b.windowHeight > 0 {
+		url.Set("window-size", fmt.Sprintf("%d,%d", b.windowWidth, b.windowHeight))
+	}
+
+	// Set proxy if provided
+	if proxy := b.proxyServer(); proxy != "" {
+		url.Proxy(proxy)
+	}
+
+	// Set the extra flags last, so they override the built-in ones
+	for name, value := range b.extraFlags {
+		url.Set(flags.Flag(name), value)
+	}
+	for _, name := range b.extraSwitches {
+		url.Set(flags.Flag(name))
+	}
+
+	return url
 }
 
 // GetPage returns a page instance from the browser pool.
 // If the browser instance is nil, it creates a new browser instance.
 // If the page pool is empty, it creates a new page instance.
 // It also resets the idle timer.
+// If every page of the pool is in use, it waits for one to be put back, see GetPageWithContext to bound the wait.
 func (b *Browser) GetPage(options ...PageOption) (*rod.Page, error) {
+	return b.GetPageWithContext(context.Background(), options...)
+}
+
+// GetPageWithContext is like GetPage, but gives up waiting for a page of the pool when the ctx is done.
+// The options only apply to pages created for the call, not to pages reused from the pool.
+// If the ctx is already done, it returns ctx.Err() without taking a page or creating one.
+func (b *Browser) GetPageWithContext(ctx context.Context, options ...PageOption) (*rod.Page, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	b.mu.Lock()
-	defer b.mu.Unlock()
+	if b.restartDue() {
+		if err := b.restart(); err != nil {
+			b.mu.Unlock()
+			return nil, err
+		}
+	}
 
 	if b.browser == nil {
-		var err error
-		b, err = createBrowser(b)
-		if err != nil {
+		if err := b.launch(); err != nil {
+			b.mu.Unlock()
 			return nil, err
 		}
 	}
@@ -292,103 +992,544 @@ func (b *Browser) GetPage(options ...PageOption) (*rod.Page, error) {
 	// Reset the timer
 	b.timer.Reset(b.idleTimeout)
 
-	// Create a new page instance from the pool or create a new page instance if the pool is empty.
-	create := func() (*rod.Page, error) {
-		page := b.browser.MustIncognito().MustPage()
+	pool := b.pool
+	b.acquiring.Add(1)
+	defer b.acquiring.Add(-1)
+	b.mu.Unlock()
+
+	// Wait for a free slot without holding the lock, so pages can be put back meanwhile
+	var page *rod.Page
+	select {
+	case page = <-*pool:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to get page from pool: %w", ctx.Err())
+	}
+
+	// The ctx may be done by the time a slot frees up, give it back rather than creating a page for nobody
+	if err := ctx.Err(); err != nil {
+		pool.Put(page)
+		return nil, err
+	}
 
-		for _, option := range options {
-			option(page)
+	// An empty slot means the page has to be created
+	if page == nil {
+		var err error
+		page, err = b.newPage(pool, options)
+		if err != nil {
+			// Give the slot back so the pool doesn't shrink
+			pool.Put(nil)
+			return nil, fmt.Errorf("failed to get page from pool: %w", err)
 		}
+	}
+	b.checkedOut.Add(1)
+	b.served.Add(1)
+	b.metrics.PageAcquired(b.label)
 
-		return page, nil
+	return page, nil
+}
+
+// restartDue reports whether the browser served its WithRestartAfter pages and can be restarted.
+// It must be called with b.mu held.
+func (b *Browser) restartDue() bool {
+	return b.restartAfter > 0 && b.browser != nil &&
+		int(b.served.Load()) >= b.restartAfter && b.quiet()
+}
+
+// quiet reports whether no page is in use or on its way in or out of the pool, and no page of a browser context
+// is in use, so the browser can be torn down without waiting for a page. It must be called with b.mu held and a running browser.
+func (b *Browser) quiet() bool {
+	return b.checkedOut.Load() == 0 && b.acquiring.Load() == 0 && len(*b.pool) == b.poolSize && len(b.contextPages) == 0
+}
+
+// restart closes the browser and launches it again in place, keeping its registration.
+// If the browser fails to close, it's kept with a full pool and the restart is tried again by the next GetPage.
+// It must be called with b.mu held.
+func (b *Browser) restart() error {
+	b.log().Info("restarting browser", "pages_served", b.served.Load())
+	if err := b.shutdown(); err != nil {
+		b.log().Warn("failed to restart browser", "key", b.key(), "error", err)
+		return nil
 	}
 
-	page, err := b.pool.Get(create)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get page from pool: %w", err)
+	return b.launch()
+}
+
+// newPage creates a page for a slot of the pool and applies the options to it.
+func (b *Browser) newPage(pool *rod.PagePool, options []PageOption) (*rod.Page, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.browser == nil || b.pool != pool {
+		return nil, errors.New("browser was closed while waiting for a page")
+	}
+
+	var page *rod.Page
+	if b.reuseInitialPage && len(b.initialPages) > 0 {
+		page, b.initialPages = b.initialPages[0], b.initialPages[1:]
+	} else if b.incognito {
+		page = b.browser.MustIncognito().MustPage()
+	} else {
+		page = b.browser.MustPage()
+	}
+	b.pages[page.TargetID] = 0
+
+	// Close the tabs opened at launch once another page exists, so closing them doesn't end the browser
+	if !b.reuseInitialPage {
+		for _, initial := range b.initialPages {
+			_ = initial.Close()
+		}
+		b.initialPages = nil
+	}
+
+	if proxy := b.nextProxy(); proxy != "" {
+		if err := b.routeThroughProxy(page, proxy); err != nil {
+			_ = page.Close()
+			return nil, err
+		}
+	}
+
+	for _, option := range options {
+		option(page)
+	}
+
+	// Answer the proxy challenges with the browser's credential, unless WithPageProxyAuth set the page's own
+	if cred := pageProxyAuth(page, b.proxyAuth); cred != nil {
+		if err := b.handleProxyAuth(page, cred); err != nil {
+			_ = page.Close()
+			return nil, fmt.Errorf("failed to handle proxy authentication: %w", err)
+		}
 	}
 
 	return page, nil
 }
 
 // PutPage puts a page instance back into the browser pool.
+// Request hijacking started on the page, such as BlockImageLoading, is stopped.
+// A page of GetPageInContext, or taken before the browser was evicted or relaunched, is closed instead.
 func (b *Browser) PutPage(page *rod.Page) {
+	b.StopHijacking(page)
+
 	b.mu.Lock()
 	b.lastUsed = time.Now()
 	b.timer.Reset(b.idleTimeout)
+	if _, ok := b.contextPages[page.TargetID]; ok {
+		delete(b.contextPages, page.TargetID)
+		b.mu.Unlock()
+
+		_ = page.Close()
+		return
+	}
+	uses, tracked := b.pages[page.TargetID]
+	if !tracked {
+		b.mu.Unlock()
+
+		// The page belongs to a browser that was evicted or relaunched since, its slot is gone with the old pool
+		_ = page.Close()
+		return
+	}
+	uses++
+	b.pages[page.TargetID] = uses
 	b.mu.Unlock()
 
+	b.checkedOut.Add(-1)
+	b.metrics.PageReleased(b.label)
+
+	if b.maxPageReuses > 0 && uses >= b.maxPageReuses {
+		b.log().Info("page reuse limit reached, recycling", "max_reuses", b.maxPageReuses)
+		b.discardPage(page)
+		return
+	}
+
+	if b.maxPageMB > 0 && b.pageHeapMB(page) > float64(b.maxPageMB) {
+		b.log().Info("page memory over limit, recycling", "max_mb", b.maxPageMB)
+		b.discardPage(page)
+		return
+	}
+
 	b.pool.Put(page)
 }
 
+// discardPage closes a page put back into the pool and frees its slot, so the pool creates a new page instead.
+func (b *Browser) discardPage(page *rod.Page) {
+	b.mu.Lock()
+	delete(b.pages, page.TargetID)
+	b.mu.Unlock()
+	_ = page.Close()
+
+	b.pool.Put(nil)
+}
+
+// pageHeapMB returns the size of the JavaScript heap used by the page in megabytes, or 0 if it can't be measured.
+func (b *Browser) pageHeapMB(page *rod.Page) float64 {
+	if err := (proto.PerformanceEnable{}).Call(page); err != nil {
+		return 0
+	}
+
+	res, err := proto.PerformanceGetMetrics{}.Call(page)
+	if err != nil {
+		return 0
+	}
+
+	for _, m := range res.Metrics {
+		if m.Name == "JSHeapUsedSize" {
+			return m.Value / (1 << 20)
+		}
+	}
+
+	return 0
+}
+
+// PruneExtraPages closes the pages of the browser that were not created by the pool,
+// such as popups or tabs opened by scripts, so the number of open pages doesn't grow beyond the pool size.
+// It returns the number of pages closed.
+func (b *Browser) PruneExtraPages() (closed int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.browser == nil {
+		return 0, nil
+	}
+
+	pages, err := b.browser.Pages()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pages: %w", err)
+	}
+
+	for _, page := range pages {
+		if _, ok := b.pages[page.TargetID]; ok {
+			continue
+		}
+		if err := page.Close(); err != nil {
+			return closed, fmt.Errorf("failed to close page: %w", err)
+		}
+		closed++
+	}
+
+	return closed, nil
+}
+
 // BlockImageLoading blocks the loading of image resources on a page.
+// The blocking stops when the page is closed or put back into the pool.
 func (b *Browser) BlockImageLoading(page *rod.Page) error {
-	router := page.HijackRequests()
-	err := router.Add("*", proto.NetworkResourceTypeImage, func(ctx *rod.Hijack) {
+	return b.BlockResourceTypes(page, proto.NetworkResourceTypeImage)
+}
+
+// BlockResourceTypes blocks the loading of resources of the given types on a page,
+// e.g. proto.NetworkResourceTypeFont, proto.NetworkResourceTypeStylesheet and proto.NetworkResourceTypeMedia.
+// The blocking stops when the page is closed or put back into the pool.
+func (b *Browser) BlockResourceTypes(page *rod.Page, types ...proto.NetworkResourceType) error {
+	if len(types) == 0 {
+		return nil
+	}
+
+	_, err := b.hijack(page, "", false, func(ctx *rod.Hijack) {
+		if !slices.Contains(types, ctx.Request.Type()) {
+			ctx.Skip = true
+			return
+		}
 		ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to block image loading: %w", err)
+		return fmt.Errorf("failed to block %v loading: %w", types, err)
+	}
+
+	return nil
+}
+
+// BlockURLPatterns blocks the requests of a page whose URL matches any of the glob patterns, whatever their type,
+// e.g. "*doubleclick.net*" or "https://*.example.com/ads/*". In patterns, "*" matches any characters and "?" a single one.
+// Hosts are matched case-insensitively. The blocking stops when the page is closed or put back into the pool.
+func (b *Browser) BlockURLPatterns(page *rod.Page, patterns ...string) error {
+	if len(patterns) == 0 {
+		return nil
 	}
 
-	go router.Run()
+	matchers := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		matchers[i] = globRegexp(lowerGlobHost(pattern))
+	}
+
+	_, err := b.hijack(page, "", false, func(ctx *rod.Hijack) {
+		if !matchesAny(matchers, lowerURLHost(ctx.Request.URL())) {
+			ctx.Skip = true
+			return
+		}
+		ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to block url patterns: %w", err)
+	}
 
 	return nil
 }
 
+// globRegexp compiles a glob pattern, where "*" matches any characters and "?" a single one, to an anchored regexp.
+func globRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.MustCompile(sb.String())
+}
+
+// lowerGlobHost lowercases the host part of a glob pattern, which is the part before the path,
+// after the scheme if there is one. A pattern without a path, such as "*tracker.com*", is lowercased entirely.
+func lowerGlobHost(pattern string) string {
+	start := 0
+	if i := strings.Index(pattern, "://"); i >= 0 {
+		start = i + len("://")
+	}
+
+	end := len(pattern)
+	if i := strings.Index(pattern[start:], "/"); i >= 0 {
+		end = start + i
+	}
+
+	return pattern[:start] + strings.ToLower(pattern[start:end]) + pattern[end:]
+}
+
+// lowerURLHost returns the url with its host lowercased.
+func lowerURLHost(u *url.URL) string {
+	lowered := *u
+	lowered.Host = strings.ToLower(u.Host)
+	return lowered.String()
+}
+
+// matchesAny reports whether s matches any of the regexps.
+func matchesAny(matchers []*regexp.Regexp, s string) bool {
+	for _, m := range matchers {
+		if m.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
 // Close closes the browser instance and all the page instances in the pool.
 // This function is thread-safe and handles potential deadlock situations.
 func (b *Browser) Close() error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if b.browser != nil {
-		// Use the official Cleanup method to iterate through the page pool and attempt to return all pages to the pool.
-		b.pool.Cleanup(func(page *rod.Page) {
-			if err := page.Close(); err != nil {
-				fmt.Println("failed to close page:", err)
-			}
-		})
+	return b.closeLocked()
+}
+
+// closeIdle closes the browser once the idle timeout elapsed. If a page was used in the meantime,
+// or is still in use, the timer is reset instead, so the pool isn't torn down under a GetPage or PutPage.
+func (b *Browser) closeIdle() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.browser == nil {
+		return
+	}
+	if !b.quiet() || time.Since(b.lastUsed) < b.idleTimeout {
+		b.timer.Reset(b.idleTimeout)
+		return
+	}
+
+	b.log().Info("browser idle, closing", "idle_timeout", b.idleTimeout)
+	if err := b.closeLocked(); err != nil {
+		b.log().Error("failed to close idle browser", "key", b.key(), "error", err)
+	}
+}
 
-		if err := b.browser.Close(); err != nil {
-			return fmt.Errorf("failed to close browser: %w", err)
+// closeLocked closes the browser and forgets it, like Close. It must be called with b.mu held.
+func (b *Browser) closeLocked() error {
+	if b.browser != nil {
+		if err := b.shutdown(); err != nil {
+			return err
 		}
-		b.browser = nil
-		b.cancel()
 
-		// Remove the browser instance from the map of browsers
+		// Remove the browser instance from the map of browsers, unless another instance was registered with its options
 		mu.Lock()
-		delete(browsers, generateKey(
-			WithProxy(b.proxy),
-			WithHeadless(b.headless),
-			WithPoolSize(b.poolSize),
-			WithIdleTimeout(b.idleTimeout),
-		))
+		if b.sharedKey != "" && browsers[b.sharedKey] == b {
+			delete(browsers, b.sharedKey)
+		}
 		mu.Unlock()
+
+		b.forgetName()
+	}
+
+	return nil
+}
+
+// shutdown closes the pages of the pool and the browser process, without forgetting the instance.
+// It must be called with b.mu held and a running browser.
+func (b *Browser) shutdown() error {
+	b.stopReaper()
+
+	// Stop the request routers before their pages go away
+	b.stopAllHijacking()
+
+	// Use the official Cleanup method to iterate through the page pool and attempt to return all pages to the pool.
+	free := len(*b.pool)
+	b.pool.Cleanup(func(page *rod.Page) {
+		if err := page.Close(); err != nil {
+			b.log().Warn("failed to close page", "key", b.key(), "error", err)
+		}
+	})
+
+	if b.remoteURL != "" {
+		// The remote browser isn't ours, only disconnect from it
+		if err := b.conn.Close(); err != nil {
+			b.keep(free)
+			return fmt.Errorf("failed to disconnect from browser: %w", err)
+		}
+		b.conn = nil
+	} else if err := b.browser.Close(); err != nil {
+		b.keep(free)
+		return fmt.Errorf("failed to close browser: %w", err)
 	}
+	b.browser = nil
+	b.initialPages = nil
+	b.contexts = nil
+	b.cancel()
+
+	b.log().Info("browser closed")
+	b.metrics.BrowserClosed(b.label)
 
 	return nil
 }
 
+// keep makes the browser usable again after shutdown failed to close it, giving the pool fresh slots
+// for the free pages it closed and restarting the reaper. It must be called with b.mu held.
+func (b *Browser) keep(free int) {
+	for i := 0; i < free; i++ {
+		b.pool.Put(nil)
+	}
+
+	if b.reaperInterval > 0 && b.reaperStop == nil {
+		b.reaperStop = make(chan struct{})
+		go b.reap(b.browser, b.reaperStop)
+	}
+}
+
 // generateKey generates a unique key for a set of options.
 // The key is a string that contains the options.
 // This key is used to identify a browser instance with the same options.
 func generateKey(options ...Option) string {
-	tempBrowser := &Browser{
-		headless:    true,
-		poolSize:    3,
-		idleTimeout: 5 * time.Minute,
-	}
+	tempBrowser := newDefaultBrowser()
 
 	for _, option := range options {
 		option(tempBrowser)
 	}
 
-	return fmt.Sprintf("%s-%t-%d-%s",
-		tempBrowser.proxy,
-		tempBrowser.headless,
-		tempBrowser.poolSize,
-		tempBrowser.idleTimeout,
-	)
+	return tempBrowser.key()
+}
+
+// browserKey holds the options that make browsers different, see Browser.key.
+type browserKey struct {
+	Proxy            string
+	ProxySchemes     map[string]string
+	ProxyRotation    []string
+	ProxyAuth        *Credential
+	Headless         bool
+	Devtools         bool
+	GPU              bool
+	Mixed            bool
+	WindowWidth      int
+	WindowHeight     int
+	Incognito        bool
+	Launcher         string
+	ExecPath         string
+	UserDataDir      string
+	RemoteURL        string
+	PoolSize         int
+	IdleTimeout      time.Duration
+	SlowMotion       time.Duration
+	ReuseInitialPage bool
+	MaxPageMB        int
+	MaxPageReuses    int
+	ReaperInterval   time.Duration
+	RestartAfter     int
+	ExtraFlags       map[string]string
+	ExtraSwitches    []string
+	Instances        int
+}
+
+// key returns the unique key of the browser options, see generateKey.
+// It's the hash of the canonical JSON encoding of every option that affects the launched browser or its pool,
+// the options left out are listed on GetBrowser.
+func (b *Browser) key() string {
+	k := browserKey{
+		Proxy:            b.proxy,
+		ProxySchemes:     b.proxySchemes,
+		ProxyRotation:    b.proxyRotation,
+		ProxyAuth:        b.proxyAuth,
+		Headless:         b.headless,
+		Devtools:         b.devtools,
+		GPU:              b.gpu,
+		Mixed:            b.mixed,
+		WindowWidth:      b.windowWidth,
+		WindowHeight:     b.windowHeight,
+		Incognito:        b.incognito,
+		ExecPath:         b.execPath,
+		UserDataDir:      b.userDataDir,
+		RemoteURL:        b.remoteURL,
+		PoolSize:         b.poolSize,
+		IdleTimeout:      b.idleTimeout,
+		SlowMotion:       b.slowMotion,
+		ReuseInitialPage: b.reuseInitialPage,
+		MaxPageMB:        b.maxPageMB,
+		MaxPageReuses:    b.maxPageReuses,
+		ReaperInterval:   b.reaperInterval,
+		RestartAfter:     b.restartAfter,
+		ExtraFlags:       b.extraFlags,
+		ExtraSwitches:    b.extraSwitches,
+		Instances:        b.reusePolicy.instances(),
+	}
+	// The order of the switches doesn't matter
+	if len(b.extraSwitches) > 0 {
+		k.ExtraSwitches = slices.Clone(b.extraSwitches)
+		slices.Sort(k.ExtraSwitches)
+	}
+	// Launchers can't be compared by value, so they're told apart by pointer
+	if b.launcher != nil {
+		k.Launcher = fmt.Sprintf("%p", b.launcher)
+	}
+
+	// Encoding a struct of plain values can't fail, and map keys are encoded sorted
+	data, _ := json.Marshal(k)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// log returns the logger of the browser, with the label attached if set.
+func (b *Browser) log() *slog.Logger {
+	if b.label != "" {
+		return b.logger.With("label", b.label)
+	}
+	return b.logger
+}
+
+// newDefaultBrowser returns a browser configured with the default options.
+func newDefaultBrowser() *Browser {
+	return &Browser{
+		headless:    true,
+		poolSize:    3,
+		idleTimeout: 5 * time.Minute,
+		slowMotion:  960 * time.Microsecond,
+		logger:      discardLogger(),
+		metrics:     nopMetrics{},
+		incognito:   true,
+	}
+}
+
+// discardLogger returns a logger that discards every event.
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
 }
@@ -1,7 +1,24 @@
 package browser
 
 import (
+	"bytes"
+	"context"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
+	"github.com/go-rod/rod/lib/proto"
 	"github.com/stretchr/testify/assert"
+	"image/png"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -33,6 +50,50 @@ func TestGenerateKeyDifferentConfigurations(t *testing.T) {
 	assert.NotEqual(t, key1, key2, "The generated keys should be different")
 }
 
+func TestGenerateKeyEveryOption(t *testing.T) {
+	options := map[string]Option{
+		"proxy":              WithProxy("127.0.0.1:8080"),
+		"proxy scheme":       WithProxyScheme(map[string]string{"https": "127.0.0.1:8443"}),
+		"proxy rotation":     WithProxyRotationPerPage("127.0.0.1:8080"),
+		"headless":           WithHeadless(false),
+		"devtools":           WithDevtools(true),
+		"gpu":                WithGPU(true),
+		"mixed content":      WithAllowMixedContent(),
+		"window size":        WithWindowSize(1600, 1000),
+		"incognito":          WithIncognito(false),
+		"launcher":           WithLauncher(launcher.New()),
+		"executable path":    WithExecutablePath("/opt/chromium/chrome"),
+		"user data dir":      WithUserDataDir("/tmp/profile"),
+		"remote url":         WithRemoteURL("ws://127.0.0.1:9222"),
+		"pool size":          WithPoolSize(5),
+		"idle timeout":       WithIdleTimeout(time.Minute),
+		"slow motion":        WithSlowMotion(0),
+		"reuse initial page": WithReuseInitialPage(true),
+		"max page memory":    WithMaxPageMemoryMB(512),
+		"max page reuses":    WithMaxPageReuses(10),
+		"reaper":             WithReaper(time.Minute),
+		"restart after":      WithRestartAfter(100),
+		"extra flags":        WithExtraLaunchFlags(map[string]string{"lang": "de-DE"}),
+		"extra switches":     WithExtraLaunchSwitches("mute-audio"),
+		"reuse policy":       WithReusePolicy(ShardWhenBusy(2)),
+	}
+
+	keys := map[string]string{generateKey(): "defaults"}
+	for name, option := range options {
+		key := generateKey(option)
+		if other, ok := keys[key]; ok {
+			t.Errorf("%s and %s have the same key", name, other)
+		}
+		keys[key] = name
+	}
+
+	// Proxy credentials only differ by password.
+	assert.NotEqual(t,
+		generateKey(WithProxy("127.0.0.1:8080"), WithProxyAuth("user", "a")),
+		generateKey(WithProxy("127.0.0.1:8080"), WithProxyAuth("user", "b")),
+	)
+}
+
 func TestNewBrowser(t *testing.T) {
 	b, err := NewBrowser()
 	assert.NoError(t, err)
@@ -193,6 +254,54 @@ func TestGetPageWithNilBrowser(t *testing.T) {
 	assert.Nil(t, b.browser)
 }
 
+func TestGetPageRelaunchKeepsRegistration(t *testing.T) {
+	b, err := GetBrowser(WithPoolSize(2))
+	assert.NoError(t, err)
+	defer func(b *Browser) {
+		_ = b.Close()
+	}(b)
+
+	assert.NoError(t, b.Close())
+
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	mu.RLock()
+	registered := browsers[b.sharedKey]
+	mu.RUnlock()
+	assert.Same(t, b, registered)
+
+	// GetBrowser hands out the relaunched instance again.
+	b2, err := GetBrowser(WithPoolSize(2))
+	assert.NoError(t, err)
+	assert.Same(t, b, b2)
+}
+
+func TestWithLazyLaunch(t *testing.T) {
+	assert.Equal(t, generateKey(), generateKey(WithLazyLaunch(true)))
+
+	b := newTestBrowser(t, WithLazyLaunch(true), WithPoolSize(2))
+
+	// Nothing runs until the first page is requested.
+	assert.Nil(t, b.browser)
+	assert.Nil(t, b.timer)
+	assert.NoError(t, b.Close())
+
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	assert.NotNil(t, b.browser)
+	assert.NotNil(t, b.timer)
+	assert.Equal(t, 1, len(*b.pool))
+
+	// The options are still checked up front.
+	b2, err := NewBrowser(WithLazyLaunch(true), WithProxyAuth("user", "pass"))
+	assert.Error(t, err)
+	assert.Nil(t, b2)
+}
+
 func TestCloseWithPagesInPool(t *testing.T) {
 	b, err := GetBrowser()
 	assert.NoError(t, err)
@@ -238,6 +347,37 @@ func TestIdleTimeout(t *testing.T) {
 	assert.Nil(t, b.browser)
 }
 
+func TestIdleTimeoutDuringGetPage(t *testing.T) {
+	b := newTestBrowser(t, WithPoolSize(2), WithIdleTimeout(time.Millisecond))
+
+	// The idle timer keeps firing while pages are taken and put back, which must neither panic nor deadlock.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				page, err := b.GetPage()
+				if !assert.NoError(t, err) {
+					return
+				}
+				_, err = page.Eval(`() => 1`)
+				assert.NoError(t, err)
+				b.PutPage(page)
+				time.Sleep(time.Duration(j%3) * time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Once nothing is in use, the idle browser is closed.
+	assert.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.browser == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 func TestPageOptions(t *testing.T) {
 	b, _ := GetBrowser(WithHeadless(false))
 	defer func(b *Browser) {
@@ -355,3 +495,666 @@ func TestWithCookies(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Nil(t, b.browser)
 }
+
+// testPages serves HTML documents keyed by path, see newTestServer.
+type testPages map[string]string
+
+func (pages testPages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	html, ok := pages[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(html))
+}
+
+// newTestServer starts a local HTTP server with the handler, e.g. testPages, closed when the test ends.
+func newTestServer(t *testing.T, handler http.Handler) *httptest.Server {
+	t.Helper()
+
+	srv := newTestServer(t, handler)
+
+	return srv
+}
+
+// newTestBrowser launches a browser with the options, closed when the test ends.
+func newTestBrowser(t *testing.T, options ...Option) *Browser {
+	t.Helper()
+
+	b, err := NewBrowser(options...)
+	if err != nil {
+		t.Fatalf("failed to launch browser: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = b.Close()
+	})
+
+	return b
+}
+
+// newTestPage launches a browser with the options and takes a page from its pool,
+// put back and closed when the test ends.
+func newTestPage(t *testing.T, options ...Option) (*Browser, *rod.Page) {
+	t.Helper()
+
+	b := newTestBrowser(t, options...)
+	page, err := b.GetPage()
+	if err != nil {
+		t.Fatalf("failed to get page: %v", err)
+	}
+	t.Cleanup(func() {
+		b.PutPage(page)
+	})
+
+	return b, page
+}
+
+func TestPruneExtraPages(t *testing.T) {
+	b := newTestBrowser(t, WithPoolSize(2))
+
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	b.PutPage(page)
+
+	// Open pages outside of the pool, like popups would do.
+	b.browser.MustPage()
+	b.browser.MustPage()
+
+	closed, err := b.PruneExtraPages()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, closed, 2)
+
+	pages, err := b.browser.Pages()
+	assert.NoError(t, err)
+	assert.Len(t, pages, 1)
+	assert.Equal(t, page.TargetID, pages[0].TargetID)
+}
+
+func TestGenerateKeyWithGPU(t *testing.T) {
+	assert.NotEqual(t, generateKey(), generateKey(WithGPU(true)))
+	assert.Equal(t, generateKey(), generateKey(WithGPU(false)))
+}
+
+func TestWithGPU(t *testing.T) {
+	b, page := newTestPage(t, WithGPU(true))
+	assert.True(t, b.gpu)
+
+	renderer := page.MustEval(`() => {
+		const gl = document.createElement('canvas').getContext('webgl');
+		if (!gl) return '';
+		const info = gl.getExtension('WEBGL_debug_renderer_info');
+		return gl.getParameter(info ? info.UNMASKED_RENDERER_WEBGL : gl.RENDERER);
+	}`).String()
+	assert.True(t, page.MustEval(`() => typeof WebGLRenderingContext !== 'undefined'`).Bool())
+	assert.NotEmpty(t, renderer)
+}
+
+func TestWithLauncher(t *testing.T) {
+	l := launcher.New().
+		Headless(true).
+		NoSandbox(true).
+		Set("js-flags", "--expose-gc")
+
+	assert.NotEqual(t, generateKey(), generateKey(WithLauncher(l)))
+	assert.Equal(t, generateKey(WithLauncher(l)), generateKey(WithLauncher(l)))
+
+	_, page := newTestPage(t, WithLauncher(l))
+
+	// The gc function is only exposed to pages by the custom flag.
+	assert.True(t, page.MustEval(`() => typeof window.gc === 'function'`).Bool())
+	assert.Zero(t, l.PID(), "The provided launcher should not be launched itself")
+}
+
+func TestWithAllowMixedContentFlag(t *testing.T) {
+	assert.NotEqual(t, generateKey(), generateKey(WithAllowMixedContent()))
+
+	b := newDefaultBrowser()
+	WithAllowMixedContent()(b)
+	assert.True(t, b.newLauncher().Has("allow-running-insecure-content"))
+}
+
+func TestWithWindowSize(t *testing.T) {
+	b := newDefaultBrowser()
+	WithWindowSize(1600, 1000)(b)
+	assert.Equal(t, "1600,1000", b.newLauncher().Get("window-size"))
+
+	b, page := newTestPage(t, WithWindowSize(1600, 1000))
+
+	data, err := b.Screenshot(page)
+	assert.NoError(t, err)
+	shot, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 1600, shot.Bounds().Dx())
+	assert.Equal(t, 1000, shot.Bounds().Dy())
+}
+
+func TestWithExtraLaunchFlags(t *testing.T) {
+	assert.Equal(t,
+		generateKey(WithExtraLaunchSwitches("mute-audio", "disable-sync")),
+		generateKey(WithExtraLaunchSwitches("disable-sync", "mute-audio")),
+	)
+	assert.NotEqual(t,
+		generateKey(WithExtraLaunchFlags(map[string]string{"lang": "de-DE"})),
+		generateKey(WithExtraLaunchFlags(map[string]string{"lang": "fr-FR"})),
+	)
+
+	b := newDefaultBrowser()
+	WithExtraLaunchFlags(map[string]string{"--lang": "de-DE", "disable-blink-features": "Other"})(b)
+	WithExtraLaunchSwitches("--mute-audio")(b)
+
+	l := b.newLauncher()
+	assert.Equal(t, "de-DE", l.Get("lang"))
+	assert.True(t, l.Has("mute-audio"))
+	// The extra flags override the built-in ones.
+	assert.Equal(t, "Other", l.Get("disable-blink-features"))
+
+	b, page := newTestPage(t, WithExtraLaunchFlags(map[string]string{"lang": "de-DE"}))
+
+	assert.Equal(t, "de-DE", page.MustEval(`() => navigator.language`).String())
+}
+
+func TestWithDevtools(t *testing.T) {
+	b := newDefaultBrowser()
+	WithHeadless(false)(b)
+	WithDevtools(true)(b)
+	assert.True(t, b.newLauncher().Has("auto-open-devtools-for-tabs"))
+
+	// Headless browsers have no window to open DevTools in.
+	var buf bytes.Buffer
+	b, err := NewBrowser(WithDevtools(true), WithLazyLaunch(true), WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+	assert.NoError(t, err)
+	assert.False(t, b.newLauncher().Has("auto-open-devtools-for-tabs"))
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), "WithDevtools")
+}
+
+func TestWithAllowMixedContent(t *testing.T) {
+	insecure := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript")
+		_, _ = w.Write([]byte(`window.insecureLoaded = true`))
+	}))
+
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body><script src="` + insecure.URL + `/insecure.js"></script></body></html>`))
+	}))
+	defer secure.Close()
+
+	_, page := newTestPage(t, WithAllowMixedContent())
+
+	page.MustNavigate(secure.URL)
+	page.MustWaitLoad()
+
+	assert.True(t, page.MustEval(`() => window.insecureLoaded === true`).Bool())
+}
+
+func TestWithReuseInitialPage(t *testing.T) {
+	assert.NotEqual(t, generateKey(), generateKey(WithReuseInitialPage(true)))
+
+	for _, reuse := range []bool{true, false} {
+		b, err := NewBrowser(WithReuseInitialPage(reuse))
+		assert.NoError(t, err)
+
+		initial, err := b.browser.Pages()
+		assert.NoError(t, err)
+
+		page, err := b.GetPage()
+		assert.NoError(t, err)
+
+		// No orphan about:blank tab should be left behind by the first GetPage.
+		pages, err := b.browser.Pages()
+		assert.NoError(t, err)
+		assert.Len(t, pages, 1)
+		assert.Equal(t, page.TargetID, pages[0].TargetID)
+
+		if reuse && assert.NotEmpty(t, initial) {
+			assert.Equal(t, initial[0].TargetID, page.TargetID, "The initial page should be reused")
+		}
+
+		b.PutPage(page)
+		assert.NoError(t, b.Close())
+	}
+}
+
+func TestResetPool(t *testing.T) {
+	b1, err := GetBrowser(WithPoolSize(1))
+	assert.NoError(t, err)
+	b2, err := GetBrowser(WithPoolSize(2))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, ActiveBrowsers(), 2)
+
+	assert.NoError(t, ResetPool())
+	assert.Equal(t, 0, ActiveBrowsers())
+	assert.Nil(t, b1.browser)
+	assert.Nil(t, b2.browser)
+}
+
+func TestWithLabelKey(t *testing.T) {
+	assert.Equal(t, generateKey(), generateKey(WithLabel("crawler")))
+	assert.Equal(t, generateKey(), generateKey(WithLogger(slog.Default())))
+}
+
+func TestWithLabel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	b, err := NewBrowser(WithLabel("crawler"), WithLogger(logger))
+	assert.NoError(t, err)
+	assert.NoError(t, b.Close())
+
+	assert.Contains(t, buf.String(), `msg="browser launched" label=crawler`)
+	assert.Contains(t, buf.String(), `msg="browser closed" label=crawler`)
+}
+
+func TestWithLoggerCloseErrors(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	b, err := NewBrowser(WithLogger(logger))
+	assert.NoError(t, err)
+
+	// A page closed behind the pool's back fails to close again.
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	assert.NoError(t, page.Close())
+	b.PutPage(page)
+
+	assert.NoError(t, b.Close())
+
+	assert.Contains(t, buf.String(), `level=WARN msg="failed to close page" key=`+b.key())
+	assert.Contains(t, buf.String(), "error=")
+}
+
+func TestWithIncognito(t *testing.T) {
+	assert.Equal(t, generateKey(), generateKey(WithIncognito(true)))
+	assert.NotEqual(t, generateKey(), generateKey(WithIncognito(false)))
+
+	srv := newTestServer(t, testPages{
+		"/": `<html><body>Incognito</body></html>`,
+	})
+
+	for _, incognito := range []bool{true, false} {
+		b, err := NewBrowser(WithPoolSize(2), WithIncognito(incognito))
+		assert.NoError(t, err)
+
+		page1, err := b.GetPage()
+		assert.NoError(t, err)
+		page2, err := b.GetPage()
+		assert.NoError(t, err)
+
+		// Pages of the default context have no browser context id of their own.
+		assert.Equal(t, incognito, page1.Browser().BrowserContextID != "")
+
+		page1.MustNavigate(srv.URL).MustWaitLoad()
+		page1.MustEval(`() => document.cookie = 'shared=1'`)
+		page2.MustNavigate(srv.URL).MustWaitLoad()
+		shared := page2.MustEval(`() => document.cookie`).String() == "shared=1"
+		assert.Equal(t, !incognito, shared, "Only pages of the default context share cookies")
+
+		b.PutPage(page1)
+		b.PutPage(page2)
+		assert.NoError(t, b.Close())
+	}
+}
+
+func TestWithCDPTrace(t *testing.T) {
+	var buf bytes.Buffer
+	b, err := NewBrowser(WithCDPTrace(&buf))
+	assert.NoError(t, err)
+
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+
+	page.MustNavigate("about:blank")
+	b.PutPage(page)
+
+	// Closing the browser stops the writes to the buffer
+	assert.NoError(t, b.Close())
+	assert.Contains(t, buf.String(), "Page.navigate")
+}
+
+func TestWithExecutablePath(t *testing.T) {
+	assert.NotEqual(t, generateKey(), generateKey(WithExecutablePath("/opt/chromium/chrome")))
+	assert.NotEqual(t,
+		generateKey(WithExecutablePath("/opt/chromium/chrome")),
+		generateKey(WithExecutablePath("/opt/chromium-beta/chrome")),
+	)
+
+	b, err := NewBrowser(WithExecutablePath(filepath.Join(t.TempDir(), "chrome")))
+	assert.Error(t, err)
+	assert.Nil(t, b)
+	assert.Contains(t, err.Error(), "failed to find browser executable")
+
+	l := newDefaultBrowser()
+	WithExecutablePath("/opt/chromium/chrome")(l)
+	assert.Equal(t, "/opt/chromium/chrome", l.newLauncher().Get(flags.Bin))
+}
+
+func TestWithUserDataDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NotEqual(t, generateKey(), generateKey(WithUserDataDir(dir)))
+	assert.NotEqual(t, generateKey(WithUserDataDir(dir)), generateKey(WithUserDataDir(t.TempDir())))
+
+	l := newDefaultBrowser()
+	WithUserDataDir(dir)(l)
+	assert.False(t, l.incognito)
+	assert.Equal(t, dir, l.newLauncher().Get(flags.UserDataDir))
+
+	srv := newTestServer(t, testPages{
+		"/": `<html><body></body></html>`,
+	})
+
+	// A persistent cookie set in a first run is there in the next one.
+	for _, want := range []string{"", "session=1"} {
+		b, err := NewBrowser(WithUserDataDir(dir))
+		assert.NoError(t, err)
+
+		page, err := b.GetPage()
+		assert.NoError(t, err)
+
+		page.MustNavigate(srv.URL).MustWaitLoad()
+		assert.Equal(t, want, page.MustEval(`() => document.cookie`).String())
+		page.MustEval(`() => document.cookie = 'session=1; max-age=3600'`)
+
+		b.PutPage(page)
+		assert.NoError(t, b.Close())
+	}
+}
+
+func TestWithDefaultTimeout(t *testing.T) {
+	assert.Equal(t, generateKey(), generateKey(WithDefaultTimeout(time.Second)))
+
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hang" {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+
+	b := newTestBrowser(t, WithDefaultTimeout(time.Second), WithPoolSize(1))
+
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+
+	start := time.Now()
+	err = page.Navigate(srv.URL + "/hang")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// Each call gets the whole timeout, however long the page has been held.
+	time.Sleep(1500 * time.Millisecond)
+	assert.NoError(t, page.Navigate(srv.URL))
+	b.PutPage(page)
+}
+
+// hangingClient stands in for a browser that never answers, its calls only return once their context is done.
+type hangingClient struct{}
+
+func (hangingClient) Event() <-chan *cdp.Event {
+	return make(chan *cdp.Event)
+}
+
+func (hangingClient) Call(ctx context.Context, _, _ string, _ interface{}) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestCallTimeout(t *testing.T) {
+	b := newDefaultBrowser()
+	assert.Equal(t, rod.CDPClient(hangingClient{}), b.callTimeout(hangingClient{}))
+
+	WithDefaultTimeout(100 * time.Millisecond)(b)
+	client := b.callTimeout(hangingClient{})
+
+	// Every call gets the timeout of its own.
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		_, err := client.Call(context.Background(), "", "Page.navigate", nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	}
+}
+
+func TestWithMaxPageMemoryMB(t *testing.T) {
+	b := newTestBrowser(t, WithPoolSize(1), WithMaxPageMemoryMB(20))
+
+	// A light page is reused.
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	first := page.TargetID
+	b.PutPage(page)
+
+	page, err = b.GetPage()
+	assert.NoError(t, err)
+	assert.Equal(t, first, page.TargetID)
+
+	// A page holding a large array is replaced.
+	page.MustEval(`() => { window.big = Array.from({ length: 5e6 }, (_, i) => i + 0.5); }`)
+	b.PutPage(page)
+
+	page, err = b.GetPage()
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+	assert.NotEqual(t, first, page.TargetID)
+}
+
+func TestCloseAll(t *testing.T) {
+	b1, err := GetBrowser(WithPoolSize(1))
+	assert.NoError(t, err)
+	b2, err := GetNamedBrowser("close-all", WithPoolSize(2))
+	assert.NoError(t, err)
+
+	assert.NoError(t, CloseAll())
+	assert.Equal(t, 0, ActiveBrowsers())
+	assert.Nil(t, b1.browser)
+	assert.Nil(t, b2.browser)
+
+	namedMu.Lock()
+	_, ok := namedBrowsers["close-all"]
+	namedMu.Unlock()
+	assert.False(t, ok)
+
+	// Nothing left to close.
+	assert.NoError(t, CloseAll())
+}
+
+func TestWithLocalStorage(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body><p id="mode"></p><script>
+			document.getElementById('mode').textContent = localStorage.getItem('beta') === 'on' ? 'beta' : 'stable';
+		</script></body></html>`,
+	})
+	other := newTestServer(t, testPages{
+		"/": `<html><body></body></html>`,
+	})
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage(WithLocalStorage(srv.URL+"/", map[string]string{"beta": "on"}))
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+	assert.Equal(t, "beta", page.MustElement("#mode").MustText())
+
+	// Values changed by the app are kept.
+	page.MustEval(`() => localStorage.setItem('beta', 'off')`)
+	page.MustNavigate(srv.URL).MustWaitLoad()
+	assert.Equal(t, "stable", page.MustElement("#mode").MustText())
+
+	// Other origins are left alone.
+	page.MustNavigate(other.URL).MustWaitLoad()
+	assert.True(t, page.MustEval(`() => localStorage.getItem('beta') === null`).Bool())
+}
+
+func TestGetPageWithContext(t *testing.T) {
+	b := newTestBrowser(t, WithPoolSize(1))
+
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+
+	// The only page is in use, so the wait times out.
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = b.GetPageWithContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// A page put back while waiting is handed out.
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		b.PutPage(page)
+	}()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := b.GetPageWithContext(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, page.TargetID, got.TargetID)
+	b.PutPage(got)
+}
+
+func TestGetPageWithCanceledContext(t *testing.T) {
+	b := newTestBrowser(t, WithPoolSize(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	page, err := b.GetPageWithContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, page)
+
+	// No page was created and no slot was taken.
+	assert.Empty(t, b.pages)
+	assert.Equal(t, 2, len(*b.pool))
+}
+
+func TestWithMaxPageReuses(t *testing.T) {
+	b := newTestBrowser(t, WithPoolSize(1), WithMaxPageReuses(2))
+
+	var targets []proto.TargetTargetID
+	for i := 0; i < 3; i++ {
+		page, err := b.GetPage()
+		assert.NoError(t, err)
+		targets = append(targets, page.TargetID)
+		b.PutPage(page)
+	}
+
+	assert.Equal(t, targets[0], targets[1])
+	assert.NotEqual(t, targets[1], targets[2], "The page should be replaced after 2 uses")
+}
+
+func TestWithControlTransport(t *testing.T) {
+	var dialed atomic.Int32
+
+	// Relay the DevTools connection through an in-memory pipe, as a unix socket proxy would.
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed.Add(1)
+
+		upstream, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		client, server := net.Pipe()
+		go func() {
+			_, _ = io.Copy(upstream, server)
+			_ = upstream.Close()
+		}()
+		go func() {
+			_, _ = io.Copy(server, upstream)
+			_ = server.Close()
+		}()
+
+		return client, nil
+	}
+
+	_, page := newTestPage(t, WithControlTransport(dial))
+
+	assert.Equal(t, "ok", page.MustEval(`() => 'ok'`).Str())
+	assert.Equal(t, int32(1), dialed.Load())
+}
+
+func TestWithRestartAfter(t *testing.T) {
+	b := newTestBrowser(t, WithPoolSize(2), WithRestartAfter(3))
+
+	first := b.browser
+	for i := 0; i < 2; i++ {
+		page, err := b.GetPage()
+		assert.NoError(t, err)
+		b.PutPage(page)
+	}
+
+	// The third page reaches the threshold, and holds the restart back while in use.
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	other, err := b.GetPage()
+	assert.NoError(t, err)
+	assert.Same(t, first, b.browser)
+	b.PutPage(other)
+
+	// The page still in use keeps working, and the browser is restarted once it's back.
+	assert.Equal(t, "ok", page.MustEval(`() => 'ok'`).String())
+	b.PutPage(page)
+
+	page, err = b.GetPage()
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+	assert.NotNil(t, b.browser)
+	assert.NotSame(t, first, b.browser)
+}
+
+func TestWithRemoteURL(t *testing.T) {
+	// Nothing listens on a closed server's address.
+	dead := newTestServer(t, http.NotFoundHandler())
+	dead.Close()
+
+	b, err := NewBrowser(WithRemoteURL(dead.URL))
+	assert.Error(t, err)
+	assert.Nil(t, b)
+
+	// Stands in for a browser running in another container.
+	remote := launcher.New().Headless(true).NoSandbox(true)
+	controlURL := remote.MustLaunch()
+	t.Cleanup(remote.Kill)
+
+	b, err = NewBrowser(WithRemoteURL(controlURL), WithHeadless(false))
+	assert.NoError(t, err)
+	assert.Nil(t, b.launched)
+
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", page.MustEval(`() => 'ok'`).String())
+	b.PutPage(page)
+
+	// Closing only disconnects, the remote browser keeps running.
+	assert.NoError(t, b.Close())
+	assert.Nil(t, b.browser)
+
+	_, page = newTestPage(t, WithRemoteURL(controlURL))
+	assert.Equal(t, "ok", page.MustEval(`() => 'ok'`).String())
+}
+
+func TestWithSlowMotion(t *testing.T) {
+	assert.Equal(t, generateKey(), generateKey(WithSlowMotion(960*time.Microsecond)))
+
+	srv := newTestServer(t, testPages{
+		"/": `<html><body><input id="input"></body></html>`,
+	})
+
+	typing := func(d time.Duration) time.Duration {
+		_, page := newTestPage(t, WithSlowMotion(d))
+
+		page.MustNavigate(srv.URL).MustWaitLoad()
+		page.MustElement("#input").MustFocus()
+
+		start := time.Now()
+		page.Keyboard.MustType(input.KeyA, input.KeyB, input.KeyC, input.KeyD, input.KeyE)
+		return time.Since(start)
+	}
+
+	// Each key press waits for the slow motion delay, unless it's disabled.
+	assert.GreaterOrEqual(t, typing(200*time.Millisecond), time.Second)
+	assert.Less(t, typing(0), time.Second)
+}
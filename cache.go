@@ -0,0 +1,110 @@
+package browser
+
+import (
+	"container/list"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// cachedResponse is a response stored by EnableResponseCache.
+type cachedResponse struct {
+	url     string
+	status  int
+	headers []*proto.FetchHeaderEntry
+	body    []byte
+}
+
+// responseCache is a least recently used cache of responses keyed by URL.
+type responseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+// get returns the response cached for the url and marks it as recently used.
+func (c *responseCache) get(url string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[url]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+
+	return el.Value.(*cachedResponse), true
+}
+
+// put stores the response, evicting the least recently used one if the cache is full.
+func (c *responseCache) put(res *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[res.url]; ok {
+		el.Value = res
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[res.url] = c.order.PushFront(res)
+	if c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedResponse).url)
+	}
+}
+
+// EnableResponseCache serves repeated GET requests of the page from an in-memory cache of up to maxEntries responses,
+// keyed by URL. On a miss the request is re-issued from Go, through the page's proxy and with its cookies,
+// and its response is cached if it's successful and not marked with Cache-Control: no-store. The cache stops when the returned stop function is called,
+// or when the page is closed or put back into the pool.
+func (b *Browser) EnableResponseCache(page *rod.Page, maxEntries int) (stop func(), err error) {
+	cache := &responseCache{
+		maxEntries: max(maxEntries, 1),
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+	client := newHijackClient(b.pageProxy(page))
+
+	remove, err := b.hijack(page, "", false, func(ctx *rod.Hijack) {
+		if ctx.Request.Method() != http.MethodGet {
+			ctx.Skip = true
+			return
+		}
+
+		url := ctx.Request.URL().String()
+		payload := ctx.Response.Payload()
+
+		if res, ok := cache.get(url); ok {
+			payload.ResponseCode = res.status
+			payload.ResponseHeaders = res.headers
+			payload.Body = res.body
+			return
+		}
+
+		if err := loadResponse(page, ctx, client); err != nil {
+			ctx.Response.Fail(proto.NetworkErrorReasonConnectionFailed)
+			return
+		}
+
+		if payload.ResponseCode == http.StatusOK &&
+			!strings.Contains(strings.ToLower(ctx.Response.Headers().Get("Cache-Control")), "no-store") {
+			cache.put(&cachedResponse{
+				url:     url,
+				status:  payload.ResponseCode,
+				headers: payload.ResponseHeaders,
+				body:    payload.Body,
+			})
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable response cache: %w", err)
+	}
+
+	return remove, nil
+}
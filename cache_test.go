@@ -0,0 +1,86 @@
+package browser
+
+import (
+	"container/list"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"html"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResponseCacheEviction(t *testing.T) {
+	cache := &responseCache{maxEntries: 2, entries: make(map[string]*list.Element), order: list.New()}
+	cache.put(&cachedResponse{url: "a"})
+	cache.put(&cachedResponse{url: "b"})
+
+	_, ok := cache.get("a")
+	assert.True(t, ok)
+
+	// b is the least recently used entry.
+	cache.put(&cachedResponse{url: "c"})
+	_, ok = cache.get("b")
+	assert.False(t, ok)
+	_, ok = cache.get("a")
+	assert.True(t, ok)
+	_, ok = cache.get("c")
+	assert.True(t, ok)
+}
+
+func TestEnableResponseCache(t *testing.T) {
+	var assetHits, privateHits atomic.Int32
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/asset.js":
+			assetHits.Add(1)
+			w.Header().Set("Content-Type", "application/javascript")
+			w.Header().Set("Cache-Control", "no-cache")
+			_, _ = w.Write([]byte(`window.asset = true`))
+		case "/private.js":
+			privateHits.Add(1)
+			w.Header().Set("Content-Type", "application/javascript")
+			w.Header().Set("Cache-Control", "no-store")
+			_, _ = w.Write([]byte(`window.private = true`))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><body><script src="/asset.js"></script><script src="/private.js"></script></body></html>`))
+		}
+	}))
+
+	b, page := newTestPage(t)
+
+	stop, err := b.EnableResponseCache(page, 10)
+	assert.NoError(t, err)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	assert.True(t, page.MustEval(`() => window.asset === true`).Bool())
+	assert.Equal(t, int32(1), assetHits.Load(), "The second load should be served from the cache")
+	assert.Equal(t, int32(2), privateHits.Load(), "no-store responses should not be cached")
+
+	stop()
+	page.MustNavigate(srv.URL).MustWaitLoad()
+	assert.Equal(t, int32(2), assetHits.Load())
+}
+
+func TestEnableResponseCacheThroughProxy(t *testing.T) {
+	// The proxy echoes the cookies it gets, so the page shows whether the cache's fetch went through it with them.
+	proxy := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body id="cookie">` + html.EscapeString(r.Header.Get("Cookie")) + `</body></html>`))
+	}))
+
+	b, page := newTestPage(t, WithProxy(strings.TrimPrefix(proxy.URL, "http://")))
+
+	page.MustSetCookies(&proto.NetworkCookieParam{Name: "session", Value: "abc", Domain: "cached.example.test", Path: "/"})
+
+	stop, err := b.EnableResponseCache(page, 10)
+	assert.NoError(t, err)
+	defer stop()
+
+	page.MustNavigate("http://cached.example.test/").MustWaitLoad()
+	assert.Equal(t, "session=abc", page.MustElement("#cookie").MustText())
+}
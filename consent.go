@@ -0,0 +1,101 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-rod/rod"
+)
+
+// consentSelectors are CSS selectors of the accept buttons used by common consent management platforms.
+var consentSelectors = []string{
+	"#onetrust-accept-btn-handler",
+	"#accept-recommended-btn-handler",
+	"#CybotCookiebotDialogBodyLevelButtonLevelOptinAllowAll",
+	"#CybotCookiebotDialogBodyButtonAccept",
+	"#didomi-notice-agree-button",
+	".fc-cta-consent",
+	".qc-cmp2-summary-buttons button[mode=primary]",
+	".cc-allow",
+	".cc-accept",
+	"[data-testid=uc-accept-all-button]",
+	"button[aria-label='Accept all']",
+}
+
+// consentTexts are the lower-cased labels of consent accept buttons, matched against the full button text.
+var consentTexts = []string{
+	"accept all",
+	"accept all cookies",
+	"accept cookies",
+	"allow all",
+	"allow all cookies",
+	"i agree",
+	"agree",
+	"accept",
+	"alle akzeptieren",
+	"tout accepter",
+	"accepter",
+	"aceptar todo",
+	"aceptar",
+	"accetta tutto",
+	"accetta",
+	"alles accepteren",
+}
+
+// consentJS clicks the first visible consent accept button it finds.
+// It retries while the document changes, because most banners are injected after the load event,
+// and gives up a few seconds after the page has loaded.
+const consentJS = `(selectors, texts) => {
+	if (window.top !== window.self) return;
+	let done = false;
+	const visible = el => {
+		const rect = el.getBoundingClientRect();
+		const style = getComputedStyle(el);
+		return rect.width > 0 && rect.height > 0 && style.visibility !== 'hidden' && style.display !== 'none';
+	};
+	const find = () => {
+		for (const selector of selectors) {
+			const el = document.querySelector(selector);
+			if (el && visible(el)) return el;
+		}
+		const candidates = document.querySelectorAll('button, a, [role=button], input[type=button], input[type=submit]');
+		for (const el of candidates) {
+			const text = (el.innerText || el.value || '').trim().toLowerCase();
+			if (texts.includes(text) && visible(el)) return el;
+		}
+		return null;
+	};
+	const attempt = () => {
+		if (done || !document.body) return;
+		const el = find();
+		if (el) {
+			done = true;
+			observer.disconnect();
+			el.click();
+		}
+	};
+	const observer = new MutationObserver(attempt);
+	const start = () => {
+		observer.observe(document.documentElement, { childList: true, subtree: true });
+		attempt();
+	};
+	if (document.readyState === 'loading') {
+		document.addEventListener('DOMContentLoaded', start);
+	} else {
+		start();
+	}
+	window.addEventListener('load', () => {
+		attempt();
+		setTimeout(() => observer.disconnect(), 5000);
+	});
+}`
+
+// WithAutoConsent tries to accept cookie consent banners once the page content is available.
+// It clicks the first visible button matching a small ruleset of well-known consent platforms
+// and common "Accept all" labels, so it is best-effort and won't handle every banner.
+func WithAutoConsent() PageOption {
+	return func(page *rod.Page) {
+		selectors, _ := json.Marshal(consentSelectors)
+		texts, _ := json.Marshal(consentTexts)
+		page.MustEvalOnNewDocument(fmt.Sprintf("(%s)(%s, %s)", consentJS, selectors, texts))
+	}
+}
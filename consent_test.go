@@ -0,0 +1,44 @@
+package browser
+
+import (
+	"github.com/go-rod/rod"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWithAutoConsent(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body>
+			<main>Content</main>
+			<script>
+				setTimeout(() => {
+					const banner = document.createElement('div');
+					banner.id = 'banner';
+					banner.innerHTML = '<p>We use cookies</p><button id="accept">Accept all</button>';
+					banner.querySelector('#accept').addEventListener('click', () => {
+						window.consented = true;
+						banner.remove();
+					});
+					document.body.appendChild(banner);
+				}, 300);
+			</script>
+		</body></html>`,
+	})
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage(WithAutoConsent())
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	page.MustNavigate(srv.URL)
+	page.MustWaitLoad()
+
+	err = page.Timeout(5 * time.Second).Wait(rod.Eval(`() => window.consented === true`))
+	assert.NoError(t, err)
+
+	has, _, err := page.Has("#banner")
+	assert.NoError(t, err)
+	assert.False(t, has, "The consent banner should be removed")
+}
@@ -0,0 +1,99 @@
+package browser
+
+import (
+	"context"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"strings"
+	"sync"
+)
+
+// ConsoleMessage is a message logged to the console of a page.
+type ConsoleMessage struct {
+	// Type is the console method, e.g. "log", "warning" or "error".
+	Type string
+	// Text is the arguments of the call, formatted and separated by spaces.
+	Text string
+}
+
+// consoleOptions configures CaptureConsole.
+type consoleOptions struct {
+	clearOnNavigate bool
+}
+
+// ConsoleOption is a function type for configuring CaptureConsole.
+type ConsoleOption func(*consoleOptions)
+
+// WithClearConsoleBufferOnNavigate discards the captured messages each time the page's main frame navigates,
+// so only the messages of the current document are reported.
+func WithClearConsoleBufferOnNavigate() ConsoleOption {
+	return func(o *consoleOptions) {
+		o.clearOnNavigate = true
+	}
+}
+
+// CaptureConsole starts capturing the console messages of the page.
+// The capture runs until the returned stop function is called, which returns the captured messages,
+// or until the page is closed.
+func (b *Browser) CaptureConsole(page *rod.Page, opts ...ConsoleOption) (stop func() []ConsoleMessage) {
+	var o consoleOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithCancel(page.GetContext())
+
+	var (
+		messages []ConsoleMessage
+		mu       sync.Mutex
+		done     = make(chan struct{})
+	)
+
+	wait := page.Context(ctx).EachEvent(func(e *proto.RuntimeConsoleAPICalled) {
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = formatConsoleArg(arg)
+		}
+
+		mu.Lock()
+		messages = append(messages, ConsoleMessage{Type: string(e.Type), Text: strings.Join(args, " ")})
+		mu.Unlock()
+	}, func(e *proto.PageFrameNavigated) {
+		if o.clearOnNavigate && e.Frame.ParentID == "" {
+			mu.Lock()
+			messages = nil
+			mu.Unlock()
+		}
+	})
+
+	go func() {
+		defer close(done)
+		wait()
+	}()
+
+	return func() []ConsoleMessage {
+		cancel()
+		<-done
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]ConsoleMessage(nil), messages...)
+	}
+}
+
+// formatConsoleArg formats an argument of a console call like the DevTools console does for primitives.
+func formatConsoleArg(arg *proto.RuntimeRemoteObject) string {
+	switch {
+	case arg.Type == proto.RuntimeRemoteObjectTypeString:
+		return arg.Value.Str()
+	case arg.UnserializableValue != "":
+		return string(arg.UnserializableValue)
+	case arg.Type == proto.RuntimeRemoteObjectTypeUndefined:
+		return "undefined"
+	case arg.Description != "":
+		return arg.Description
+	default:
+		return arg.Value.JSON("", "")
+	}
+}
@@ -0,0 +1,33 @@
+package browser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCaptureConsole(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/first":  `<html><body><script>console.log('first page', 1); console.error('first error')</script></body></html>`,
+		"/second": `<html><body><script>console.warn('second page', true)</script></body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	all := b.CaptureConsole(page)
+	current := b.CaptureConsole(page, WithClearConsoleBufferOnNavigate())
+
+	page.MustNavigate(srv.URL + "/first").MustWaitLoad()
+	page.MustNavigate(srv.URL + "/second").MustWaitLoad()
+	time.Sleep(200 * time.Millisecond)
+
+	assert.Equal(t, []ConsoleMessage{
+		{Type: "log", Text: "first page 1"},
+		{Type: "error", Text: "first error"},
+		{Type: "warning", Text: "second page true"},
+	}, all())
+
+	assert.Equal(t, []ConsoleMessage{
+		{Type: "warning", Text: "second page true"},
+	}, current())
+}
@@ -0,0 +1,111 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"time"
+)
+
+// Credential is a username and password, e.g. to authenticate with a proxy.
+type Credential struct {
+	Username string
+	Password string
+}
+
+// ContextID identifies a browser context created by NewContextWithProxy.
+type ContextID proto.BrowserBrowserContextID
+
+// NewContextWithProxy creates an isolated browser context whose pages go through the proxy,
+// so one browser process can use several proxies at once. The proxy takes the same forms as WithProxy,
+// and auth, if not nil, answers the proxy's authentication challenges.
+// Pages of the context are created with GetPageInContext. The context lives until DisposeContext is called
+// or the browser is closed.
+func (b *Browser) NewContextWithProxy(proxy string, auth *Credential) (ContextID, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.browser == nil {
+		if err := b.launch(); err != nil {
+			return "", err
+		}
+	}
+
+	res, err := proto.TargetCreateBrowserContext{ProxyServer: proxy}.Call(b.browser)
+	if err != nil {
+		return "", fmt.Errorf("failed to create browser context with proxy %s: %w", proxy, err)
+	}
+
+	id := ContextID(res.BrowserContextID)
+	if b.contexts == nil {
+		b.contexts = make(map[ContextID]*Credential)
+	}
+	b.contexts[id] = auth
+
+	return id, nil
+}
+
+// GetPageInContext creates a page in the browser context created by NewContextWithProxy.
+// The page isn't taken from the pool, PutPage closes it instead. Until then it counts as in use,
+// so the browser isn't closed when idle or restarted.
+func (b *Browser) GetPageInContext(id ContextID, options ...PageOption) (*rod.Page, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	auth, ok := b.contexts[id]
+	if !ok || b.browser == nil {
+		return nil, errors.New("unknown browser context, it may have been closed with the browser")
+	}
+
+	b.lastUsed = time.Now()
+	b.timer.Reset(b.idleTimeout)
+
+	contextBrowser := *b.browser
+	contextBrowser.BrowserContextID = proto.BrowserBrowserContextID(id)
+
+	page, err := contextBrowser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create page in browser context: %w", err)
+	}
+	b.contextPages[page.TargetID] = id
+
+	for _, option := range options {
+		option(page)
+	}
+
+	if cred := pageProxyAuth(page, auth); cred != nil {
+		if err := b.handleProxyAuth(page, cred); err != nil {
+			delete(b.contextPages, page.TargetID)
+			_ = page.Close()
+			return nil, fmt.Errorf("failed to handle proxy authentication: %w", err)
+		}
+	}
+
+	return page, nil
+}
+
+// DisposeContext closes the pages of the browser context created by NewContextWithProxy and disposes of the context.
+func (b *Browser) DisposeContext(id ContextID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.contexts[id]; !ok || b.browser == nil {
+		return errors.New("unknown browser context, it may have been closed with the browser")
+	}
+
+	// Disposing of the context closes its pages
+	for target, context := range b.contextPages {
+		if context == id {
+			delete(b.contextPages, target)
+		}
+	}
+	delete(b.contexts, id)
+
+	err := proto.TargetDisposeBrowserContext{BrowserContextID: proto.BrowserBrowserContextID(id)}.Call(b.browser)
+	if err != nil {
+		return fmt.Errorf("failed to dispose of browser context: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package browser
+
+import (
+	"github.com/go-rod/rod"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewContextWithProxy(t *testing.T) {
+	proxyA := newFakeProxy(t, "proxy-a")
+
+	// proxyB requires authentication.
+	proxyB := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != "Basic dXNlcjpzZWNyZXQ=" {
+			w.Header().Set("Proxy-Authenticate", `Basic realm="proxy"`)
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body id="ip">proxy-b</body></html>`))
+	}))
+
+	b := newTestBrowser(t)
+
+	contextA, err := b.NewContextWithProxy(proxyA.URL, nil)
+	assert.NoError(t, err)
+	contextB, err := b.NewContextWithProxy(proxyB.URL, &Credential{Username: "user", Password: "secret"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, contextA, contextB)
+
+	pageA, err := b.GetPageInContext(contextA)
+	assert.NoError(t, err)
+	defer b.PutPage(pageA)
+
+	pageB, err := b.GetPageInContext(contextB)
+	assert.NoError(t, err)
+
+	pageA.MustNavigate("http://ip.example.test/").MustWaitLoad()
+	pageB.MustNavigate("http://ip.example.test/").MustWaitLoad()
+
+	assert.Equal(t, "proxy-a", pageA.MustElement("#ip").MustText())
+	assert.Equal(t, "proxy-b", pageB.MustElement("#ip").MustText())
+
+	_, err = b.GetPageInContext(ContextID("unknown"))
+	assert.Error(t, err)
+
+	// Disposing of the context closes its page, which no longer keeps the browser busy.
+	assert.NoError(t, b.DisposeContext(contextB))
+	b.mu.Lock()
+	assert.NotContains(t, b.contextPages, pageB.TargetID)
+	b.mu.Unlock()
+	_, err = b.GetPageInContext(contextB)
+	assert.Error(t, err)
+}
+
+func TestPutPageInContext(t *testing.T) {
+	dead := rod.New().Client(deadClient{})
+	assert.Error(t, dead.Connect())
+
+	b := newDefaultBrowser()
+	b.start(dead, nil)
+	b.timer = time.AfterFunc(time.Hour, func() {})
+	defer b.timer.Stop()
+
+	// A page of a context is in use until it's put back.
+	page := dead.PageFromSession("context-page")
+	page.TargetID = "context-page"
+	b.contextPages[page.TargetID] = ContextID("context")
+	assert.False(t, b.quiet())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.PutPage(page)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("PutPage blocked on the full pool")
+	}
+
+	assert.Empty(t, b.contextPages)
+	assert.Equal(t, b.poolSize, len(*b.pool))
+	assert.True(t, b.quiet())
+}
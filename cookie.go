@@ -0,0 +1,291 @@
+package browser
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// String returns the cookie formatted as the value of a Set-Cookie header,
+// including its Domain, Path, Expires, HttpOnly, Secure and SameSite attributes.
+// Session cookies, whose expiry is before the Unix epoch, are formatted without Expires.
+func (c Cookie) String() string {
+	cookie := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		HttpOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+	}
+
+	if c.Expires.After(time.Unix(0, 0)) {
+		cookie.Expires = c.Expires
+	}
+
+	switch c.SameSite {
+	case proto.NetworkCookieSameSiteStrict:
+		cookie.SameSite = http.SameSiteStrictMode
+	case proto.NetworkCookieSameSiteLax:
+		cookie.SameSite = http.SameSiteLaxMode
+	case proto.NetworkCookieSameSiteNone:
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+
+	return cookie.String()
+}
+
+// CookiesToHeader returns the cookies formatted as the value of a Cookie request header, e.g. "a=1; b=2".
+// Only names and values are sent in a request, so the other attributes are left out.
+func CookiesToHeader(cookies []Cookie) string {
+	pairs := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		pairs = append(pairs, (&http.Cookie{Name: c.Name, Value: c.Value}).String())
+	}
+
+	return strings.Join(pairs, "; ")
+}
+
+// CaptureSetCookies starts recording the cookies set by the Set-Cookie headers of the page's responses,
+// in the order they're received, including cookies that are later overwritten or deleted.
+// Cookies without a Domain attribute get the host of the response. The capture runs until the returned
+// stop function is called, which returns the cookies, or until the page is closed.
+func (b *Browser) CaptureSetCookies(page *rod.Page) (stop func() []Cookie) {
+	ctx, cancel := context.WithCancel(page.GetContext())
+
+	var (
+		hosts   = make(map[proto.NetworkRequestID]string)
+		cookies []Cookie
+		mu      sync.Mutex
+		done    = make(chan struct{})
+	)
+
+	wait := page.Context(ctx).EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		if u, err := url.Parse(e.Request.URL); err == nil {
+			hosts[e.RequestID] = u.Hostname()
+		}
+	}, func(e *proto.NetworkResponseReceivedExtraInfo) {
+		var lines []string
+		for name, value := range e.Headers {
+			if strings.EqualFold(name, "Set-Cookie") {
+				// Multiple Set-Cookie headers are joined by new lines
+				lines = append(lines, strings.Split(value.Str(), "\n")...)
+			}
+		}
+		if len(lines) == 0 {
+			return
+		}
+
+		parsed := (&http.Response{Header: http.Header{"Set-Cookie": lines}}).Cookies()
+
+		mu.Lock()
+		for _, c := range parsed {
+			cookies = append(cookies, fromHTTPCookie(c, hosts[e.RequestID]))
+		}
+		mu.Unlock()
+	})
+
+	go func() {
+		defer close(done)
+		wait()
+	}()
+
+	return func() []Cookie {
+		cancel()
+		<-done
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]Cookie(nil), cookies...)
+	}
+}
+
+// fromHTTPCookie converts a cookie parsed from a Set-Cookie header, defaulting its domain to host.
+func fromHTTPCookie(c *http.Cookie, host string) Cookie {
+	cookie := Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		Expires:  c.Expires,
+		HTTPOnly: c.HttpOnly,
+		Secure:   c.Secure,
+	}
+
+	if cookie.Domain == "" {
+		cookie.Domain = host
+	}
+
+	// Max-Age takes precedence over Expires, a negative one deletes the cookie
+	if c.MaxAge > 0 {
+		cookie.Expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second)
+	} else if c.MaxAge < 0 {
+		cookie.Expires = time.Unix(0, 0)
+	}
+
+	switch c.SameSite {
+	case http.SameSiteStrictMode:
+		cookie.SameSite = proto.NetworkCookieSameSiteStrict
+	case http.SameSiteLaxMode:
+		cookie.SameSite = proto.NetworkCookieSameSiteLax
+	case http.SameSiteNoneMode:
+		cookie.SameSite = proto.NetworkCookieSameSiteNone
+	}
+
+	return cookie
+}
+
+// SaveCookies saves the cookies of the page to the file at path as JSON, e.g. to restore a logged-in session
+// in a later run with LoadCookies and WithCookies. The file is only readable by the current user.
+func (b *Browser) SaveCookies(page *rod.Page, path string) error {
+	cookies, err := b.GetCookies(page)
+	if err != nil {
+		return fmt.Errorf("failed to get cookies: %w", err)
+	}
+
+	return writeCookies(path, cookies)
+}
+
+// writeCookies writes the cookies to the file at path as JSON.
+func writeCookies(path string, cookies []Cookie) error {
+	data, err := json.MarshalIndent(cookies, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cookies: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save cookies: %w", err)
+	}
+
+	return nil
+}
+
+// LoadCookies loads the cookies saved by SaveCookies from the file at path, to be set with WithCookies.
+// Expiry times are kept as saved, so session cookies stay session cookies and persistent ones keep their expiry.
+func LoadCookies(path string) ([]Cookie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cookies: %w", err)
+	}
+
+	var cookies []Cookie
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return nil, fmt.Errorf("failed to decode cookies: %w", err)
+	}
+
+	return cookies, nil
+}
+
+// httpOnlyPrefix marks the HttpOnly cookies of a Netscape cookie file, in front of their domain.
+const httpOnlyPrefix = "#HttpOnly_"
+
+// ParseNetscapeCookies parses a cookie file in the Netscape cookies.txt format used by curl, wget and yt-dlp,
+// where each line holds the domain, include subdomains flag, path, secure flag, expiry, name and value of a cookie,
+// separated by tabs. Domains of cookies that include subdomains are given a leading dot,
+// cookies without expiry become session cookies, and the "#HttpOnly_" domain prefix sets HTTPOnly.
+func ParseNetscapeCookies(r io.Reader) ([]Cookie, error) {
+	var cookies []Cookie
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+		if httpOnly {
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		} else if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 7)
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid cookie on line %d: expected 7 tab-separated fields, got %d", n, len(fields))
+		}
+
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie expiry on line %d: %w", n, err)
+		}
+
+		domain := fields[0]
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(domain, ".") {
+			domain = "." + domain
+		}
+
+		cookie := Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Domain:   domain,
+			Path:     fields[2],
+			Expires:  time.Unix(expires, 0),
+			HTTPOnly: httpOnly,
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+		}
+		// Session cookies have no expiry, which is an expiry before the Unix epoch for Cookie
+		if expires <= 0 {
+			cookie.Expires = time.Unix(-1, 0)
+		}
+
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cookies: %w", err)
+	}
+
+	return cookies, nil
+}
+
+// WriteNetscapeCookies writes the cookies in the Netscape cookies.txt format, see ParseNetscapeCookies.
+// Cookies whose domain has a leading dot include subdomains, and session cookies are written without expiry.
+func WriteNetscapeCookies(w io.Writer, cookies []Cookie) error {
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString("# Netscape HTTP Cookie File\n")
+
+	for _, c := range cookies {
+		domain := c.Domain
+		if c.HTTPOnly {
+			domain = httpOnlyPrefix + domain
+		}
+
+		var expires int64
+		if c.Expires.After(time.Unix(0, 0)) {
+			expires = c.Expires.Unix()
+		}
+
+		_, _ = fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain,
+			netscapeBool(strings.HasPrefix(c.Domain, ".")),
+			c.Path,
+			netscapeBool(c.Secure),
+			expires,
+			c.Name,
+			c.Value,
+		)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write cookies: %w", err)
+	}
+
+	return nil
+}
+
+// netscapeBool formats a flag of a Netscape cookie file.
+func netscapeBool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
@@ -0,0 +1,198 @@
+package browser
+
+import (
+	"bytes"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseSetCookie parses a Set-Cookie header value the way an HTTP client would.
+func parseSetCookie(t *testing.T, value string) *http.Cookie {
+	t.Helper()
+
+	cookies := (&http.Response{Header: http.Header{"Set-Cookie": {value}}}).Cookies()
+	if !assert.Len(t, cookies, 1, "Set-Cookie value %q should be valid", value) {
+		t.FailNow()
+	}
+
+	return cookies[0]
+}
+
+func TestCookieString(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	cookie := Cookie{
+		Name:     "session",
+		Value:    "abc123",
+		Domain:   "example.com",
+		Path:     "/app",
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: proto.NetworkCookieSameSiteStrict,
+	}
+
+	parsed := parseSetCookie(t, cookie.String())
+	assert.Equal(t, "session", parsed.Name)
+	assert.Equal(t, "abc123", parsed.Value)
+	assert.Equal(t, "example.com", parsed.Domain)
+	assert.Equal(t, "/app", parsed.Path)
+	assert.True(t, expires.Equal(parsed.Expires))
+	assert.True(t, parsed.HttpOnly)
+	assert.True(t, parsed.Secure)
+	assert.Equal(t, http.SameSiteStrictMode, parsed.SameSite)
+
+	// Session cookies reported by the browser expire before the epoch and must not be formatted as expired.
+	session := parseSetCookie(t, Cookie{Name: "id", Value: "1", Expires: time.Unix(-1, 0)}.String())
+	assert.True(t, session.Expires.IsZero())
+	assert.Equal(t, http.SameSite(0), session.SameSite)
+}
+
+func TestCookiesToHeader(t *testing.T) {
+	header := CookiesToHeader([]Cookie{
+		{Name: "a", Value: "1", Domain: "example.com", Secure: true},
+		{Name: "b", Value: "2"},
+	})
+	assert.Equal(t, "a=1; b=2", header)
+
+	req := &http.Request{Header: http.Header{"Cookie": {header}}}
+	assert.Len(t, req.Cookies(), 2)
+	assert.Equal(t, "", CookiesToHeader(nil))
+}
+
+func TestFromHTTPCookie(t *testing.T) {
+	c := fromHTTPCookie(&http.Cookie{Name: "a", Value: "1", MaxAge: 60, SameSite: http.SameSiteLaxMode}, "example.com")
+	assert.Equal(t, "example.com", c.Domain)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), c.Expires, 5*time.Second)
+	assert.Equal(t, proto.NetworkCookieSameSiteLax, c.SameSite)
+
+	c = fromHTTPCookie(&http.Cookie{Name: "a", Domain: "other.com", MaxAge: -1}, "example.com")
+	assert.Equal(t, "other.com", c.Domain)
+	assert.True(t, c.Expires.Equal(time.Unix(0, 0)))
+}
+
+func TestCaptureSetCookies(t *testing.T) {
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/", HttpOnly: true})
+			http.SetCookie(w, &http.Cookie{Name: "tracking", Value: "xyz", Path: "/"})
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><body><script>fetch('/logout')</script></body></html>`))
+		case "/logout":
+			// Delete one of the cookies right away.
+			http.SetCookie(w, &http.Cookie{Name: "tracking", Value: "", Path: "/", MaxAge: -1})
+		}
+	}))
+
+	b, page := newTestPage(t)
+
+	stop := b.CaptureSetCookies(page)
+	page.MustNavigate(srv.URL).MustWaitLoad()
+	time.Sleep(500 * time.Millisecond)
+	cookies := stop()
+
+	values := make(map[string][]string)
+	for _, c := range cookies {
+		values[c.Name] = append(values[c.Name], c.Value)
+		assert.Equal(t, "127.0.0.1", c.Domain)
+	}
+	assert.Equal(t, []string{"abc"}, values["session"])
+	assert.Equal(t, []string{"xyz", ""}, values["tracking"])
+
+	// The deleted cookie is gone from the jar but was still captured.
+	current, err := b.GetCookies(page)
+	assert.NoError(t, err)
+	for _, c := range current {
+		assert.NotEqual(t, "tracking", c.Name)
+	}
+}
+
+func TestLoadCookies(t *testing.T) {
+	cookies := []Cookie{
+		{Name: "session", Value: "abc", Domain: "example.com", Path: "/", Expires: time.Unix(-1, 0)},
+		{Name: "remember", Value: "1", Domain: "example.com", Path: "/", Expires: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), Secure: true},
+		{Name: "zero", Value: "z", Domain: "example.com", Path: "/", SameSite: proto.NetworkCookieSameSiteLax, HTTPOnly: true},
+	}
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	assert.NoError(t, writeCookies(path, cookies))
+
+	loaded, err := LoadCookies(path)
+	assert.NoError(t, err)
+	assert.Len(t, loaded, len(cookies))
+	for i, c := range loaded {
+		assert.True(t, cookies[i].Expires.Equal(c.Expires), c.Name)
+		c.Expires = cookies[i].Expires
+		assert.Equal(t, cookies[i], c)
+	}
+
+	_, err = LoadCookies(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestSaveCookies(t *testing.T) {
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/", MaxAge: 3600})
+		}
+		_, _ = w.Write([]byte(`<html><body></body></html>`))
+	}))
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	page.MustNavigate(srv.URL + "/login").MustWaitLoad()
+	assert.NoError(t, b.SaveCookies(page, path))
+	b.PutPage(page)
+
+	// A page of a fresh incognito context gets the session back.
+	cookies, err := LoadCookies(path)
+	assert.NoError(t, err)
+
+	restored, err := b.GetPage(WithCookies(cookies...))
+	assert.NoError(t, err)
+	defer b.PutPage(restored)
+
+	restored.MustNavigate(srv.URL).MustWaitLoad()
+	assert.Equal(t, "session=abc", restored.MustEval(`() => document.cookie`).Str())
+}
+
+func TestNetscapeCookies(t *testing.T) {
+	file := "# Netscape HTTP Cookie File\n" +
+		"# This is a comment\n" +
+		"\n" +
+		".example.com\tTRUE\t/\tTRUE\t4102444800\tremember\t1\n" +
+		"#HttpOnly_example.com\tFALSE\t/account\tFALSE\t0\tsession\tabc\tdef\r\n" +
+		"sub.example.org\tTRUE\t/\tFALSE\t0\tshared\tx\n"
+
+	cookies, err := ParseNetscapeCookies(strings.NewReader(file))
+	assert.NoError(t, err)
+	assert.Equal(t, []Cookie{
+		{Name: "remember", Value: "1", Domain: ".example.com", Path: "/", Expires: time.Unix(4102444800, 0), Secure: true},
+		{Name: "session", Value: "abc\tdef", Domain: "example.com", Path: "/account", Expires: time.Unix(-1, 0), HTTPOnly: true},
+		{Name: "shared", Value: "x", Domain: ".sub.example.org", Path: "/", Expires: time.Unix(-1, 0)},
+	}, cookies)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteNetscapeCookies(&buf, cookies))
+	assert.Equal(t, "# Netscape HTTP Cookie File\n"+
+		".example.com\tTRUE\t/\tTRUE\t4102444800\tremember\t1\n"+
+		"#HttpOnly_example.com\tFALSE\t/account\tFALSE\t0\tsession\tabc\tdef\n"+
+		".sub.example.org\tTRUE\t/\tFALSE\t0\tshared\tx\n", buf.String())
+
+	// The output parses back to the same cookies.
+	again, err := ParseNetscapeCookies(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, cookies, again)
+
+	_, err = ParseNetscapeCookies(strings.NewReader("example.com\tFALSE\t/\n"))
+	assert.Error(t, err)
+}
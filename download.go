@@ -0,0 +1,121 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrNotDownload is returned by CaptureDownload when the action navigated the page instead of downloading a file.
+var ErrNotDownload = errors.New("action navigated the page instead of downloading")
+
+// Download is a file downloaded by a page.
+type Download struct {
+	URL               string
+	SuggestedFilename string
+	Data              []byte
+}
+
+// CaptureDownload runs the action, such as clicking a link, and waits for the download it triggers.
+// Navigations to responses the browser doesn't render, because of an attachment Content-Disposition
+// or a non-HTML content type, become downloads, including those opened in a new tab by target=_blank links.
+// If the action makes the page itself load a document instead, ErrNotDownload is returned.
+// The timeout bounds the action and the download.
+func (b *Browser) CaptureDownload(page *rod.Page, timeout time.Duration, action func() error) (*Download, error) {
+	dir, err := os.MkdirTemp("", "browser-download-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	browser := page.Browser()
+	err = proto.BrowserSetDownloadBehavior{
+		Behavior:         proto.BrowserSetDownloadBehaviorBehaviorAllowAndName,
+		BrowserContextID: browser.BrowserContextID,
+		DownloadPath:     dir,
+		EventsEnabled:    true,
+	}.Call(browser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set download behavior: %w", err)
+	}
+	defer func() {
+		_ = proto.BrowserSetDownloadBehavior{
+			Behavior:         proto.BrowserSetDownloadBehaviorBehaviorDefault,
+			BrowserContextID: browser.BrowserContextID,
+		}.Call(browser)
+	}()
+
+	ctx, cancel := context.WithTimeout(page.GetContext(), timeout)
+	defer cancel()
+
+	// Downloads started by the page's main frame or by the tabs it opens belong to the page
+	frames := map[proto.PageFrameID]bool{page.FrameID: true}
+	var (
+		start *proto.BrowserDownloadWillBegin
+		state proto.BrowserDownloadProgressState
+	)
+	waitDownload := browser.Context(ctx).EachEvent(func(e *proto.TargetTargetCreated) {
+		if e.TargetInfo.OpenerID == page.TargetID {
+			frames[proto.PageFrameID(e.TargetInfo.TargetID)] = true
+		}
+	}, func(e *proto.BrowserDownloadWillBegin) {
+		if start == nil && frames[e.FrameID] {
+			start = e
+		}
+	}, func(e *proto.BrowserDownloadProgress) bool {
+		if start != nil && e.GUID == start.GUID && e.State != proto.BrowserDownloadProgressStateInProgress {
+			state = e.State
+			return true
+		}
+		return false
+	})
+
+	// A committed navigation of the main frame means the response was rendered as a page
+	navigated := make(chan struct{})
+	waitNavigation := page.Context(ctx).EachEvent(func(e *proto.PageFrameNavigated) bool {
+		return e.Frame.ParentID == ""
+	})
+	go func() {
+		waitNavigation()
+		if ctx.Err() == nil {
+			close(navigated)
+			cancel()
+		}
+	}()
+
+	if err := action(); err != nil {
+		return nil, fmt.Errorf("failed to run download action: %w", err)
+	}
+
+	waitDownload()
+
+	select {
+	case <-navigated:
+		return nil, ErrNotDownload
+	default:
+	}
+	if state == "" {
+		return nil, fmt.Errorf("failed to await download: %w", ctx.Err())
+	}
+	if state != proto.BrowserDownloadProgressStateCompleted {
+		return nil, fmt.Errorf("download of %s was %s", start.URL, state)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, start.GUID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download of %s: %w", start.URL, err)
+	}
+
+	return &Download{
+		URL:               start.URL,
+		SuggestedFilename: start.SuggestedFilename,
+		Data:              data,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package browser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCaptureDownload(t *testing.T) {
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/report.csv":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", `attachment; filename="report.csv"`)
+			_, _ = w.Write([]byte("id,name\n1,widget\n"))
+		case "/next":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><head><title>Next</title></head></html>`))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><body>
+				<a id="download" href="/report.csv" target="_blank">Download</a>
+				<a id="next" href="/next">Next</a>
+			</body></html>`))
+		}
+	}))
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL)
+	page.MustWaitLoad()
+
+	download, err := b.CaptureDownload(page, 10*time.Second, func() error {
+		page.MustElement("#download").MustClick()
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, download) {
+		assert.Equal(t, srv.URL+"/report.csv", download.URL)
+		assert.Equal(t, "report.csv", download.SuggestedFilename)
+		assert.Equal(t, "id,name\n1,widget\n", string(download.Data))
+	}
+	assert.Equal(t, srv.URL+"/", page.MustInfo().URL, "The download should not navigate the page")
+
+	_, err = b.CaptureDownload(page, 10*time.Second, func() error {
+		page.MustElement("#next").MustClick()
+		return nil
+	})
+	assert.True(t, errors.Is(err, ErrNotDownload))
+}
@@ -0,0 +1,80 @@
+package browser
+
+import (
+	"fmt"
+	"github.com/go-rod/rod"
+	"time"
+)
+
+// FindByXPath returns the elements of the page matching the XPath expression, without waiting for them.
+// It returns an empty slice when nothing matches.
+func (b *Browser) FindByXPath(page *rod.Page, xpath string) ([]*rod.Element, error) {
+	elements, err := page.ElementsX(xpath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find elements by xpath %s: %w", xpath, err)
+	}
+	if elements == nil {
+		return []*rod.Element{}, nil
+	}
+
+	return elements, nil
+}
+
+// WaitForXPath waits until an element of the page matches the XPath expression and returns the first match.
+func (b *Browser) WaitForXPath(page *rod.Page, xpath string, timeout time.Duration) (*rod.Element, error) {
+	p := page.Timeout(timeout)
+	defer p.CancelTimeout()
+
+	el, err := p.ElementX(xpath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for xpath %s: %w", xpath, err)
+	}
+
+	// Detach the element from the timeout of the wait
+	return el.Context(page.GetContext()), nil
+}
+
+// dismissOverlaysJS removes the fixed or absolute elements with a high z-index that cover most of the viewport,
+// and unlocks the scrolling of the document that overlays usually disable.
+const dismissOverlaysJS = `(minZIndex, minCoverage) => {
+	const viewport = window.innerWidth * window.innerHeight;
+	let removed = 0;
+	for (const el of Array.from(document.body.querySelectorAll('*'))) {
+		if (!document.contains(el)) continue;
+		const style = getComputedStyle(el);
+		if (style.position !== 'fixed' && style.position !== 'absolute') continue;
+		if ((parseInt(style.zIndex, 10) || 0) < minZIndex) continue;
+		const rect = el.getBoundingClientRect();
+		const width = Math.max(0, Math.min(rect.right, window.innerWidth) - Math.max(rect.left, 0));
+		const height = Math.max(0, Math.min(rect.bottom, window.innerHeight) - Math.max(rect.top, 0));
+		if (width * height < viewport * minCoverage) continue;
+		el.remove();
+		removed++;
+	}
+	if (removed > 0) {
+		for (const el of [document.documentElement, document.body]) {
+			if (getComputedStyle(el).overflow === 'hidden') el.style.setProperty('overflow', 'auto', 'important');
+		}
+	}
+	return removed;
+}`
+
+// Thresholds of DismissOverlays.
+const (
+	overlayMinZIndex   = 100
+	overlayMinCoverage = 0.5
+)
+
+// DismissOverlays removes the modal overlays blocking the page, such as newsletter popups and paywall curtains,
+// and returns how many elements were removed.
+// It's a heuristic: any fixed or absolute element with a z-index of at least 100 covering at least half
+// of the viewport is considered an overlay, so it may remove legitimate content and miss some overlays.
+// Scrolling of the document is unlocked if an overlay was removed.
+func (b *Browser) DismissOverlays(page *rod.Page) (int, error) {
+	res, err := page.Eval(dismissOverlaysJS, overlayMinZIndex, overlayMinCoverage)
+	if err != nil {
+		return 0, fmt.Errorf("failed to dismiss overlays: %w", err)
+	}
+
+	return res.Value.Int(), nil
+}
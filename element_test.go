@@ -0,0 +1,73 @@
+package browser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFindByXPath(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body>
+			<ul><li>One</li><li class="pick">Two</li><li>Three</li></ul>
+			<script>setTimeout(() => {
+				const p = document.createElement('p');
+				p.textContent = 'Late';
+				document.body.append(p);
+			}, 500)</script>
+		</body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	elements, err := b.FindByXPath(page, `//li[contains(@class, "pick")]`)
+	assert.NoError(t, err)
+	if assert.Len(t, elements, 1) {
+		assert.Equal(t, "Two", elements[0].MustText())
+	}
+
+	elements, err = b.FindByXPath(page, `//table`)
+	assert.NoError(t, err)
+	assert.NotNil(t, elements)
+	assert.Empty(t, elements)
+
+	el, err := b.WaitForXPath(page, `//p[text()="Late"]`, 5*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "Late", el.MustText())
+
+	_, err = b.WaitForXPath(page, `//table`, 500*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestDismissOverlays(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body style="overflow: hidden">
+			<button id="button" onclick="window.clicked = true">Click</button>
+			<div id="badge" style="position: fixed; top: 0; right: 0; width: 50px; height: 50px; z-index: 9999"></div>
+			<div id="overlay" style="position: fixed; inset: 0; background: rgba(0, 0, 0, 0.5); z-index: 1000">
+				<div style="position: absolute; top: 40%; left: 40%; width: 20%; height: 20%; background: white; z-index: 1001">Subscribe!</div>
+			</div>
+		</body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	// The overlay intercepts the click.
+	covered, err := page.MustElement("#button").Interactable()
+	assert.Error(t, err)
+	assert.Nil(t, covered)
+
+	removed, err := b.DismissOverlays(page)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.False(t, page.MustHas("#overlay"))
+	assert.True(t, page.MustHas("#badge"), "Small fixed elements are not overlays")
+
+	page.MustElement("#button").MustClick()
+	assert.True(t, page.MustEval(`() => window.clicked === true`).Bool())
+	assert.Equal(t, "auto", page.MustEval(`() => getComputedStyle(document.body).overflow`).String())
+}
@@ -0,0 +1,60 @@
+package browser
+
+import (
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// WithVisionDeficiency renders the page as seen by people with the vision deficiency, for accessibility audits.
+// The deficiency is one of "blurredVision", "reducedContrast", "achromatopsia", "deuteranopia",
+// "protanopia" or "tritanopia", or "none" to render the page normally.
+func WithVisionDeficiency(deficiency string) PageOption {
+	return func(page *rod.Page) {
+		err := proto.EmulationSetEmulatedVisionDeficiency{
+			Type: proto.EmulationSetEmulatedVisionDeficiencyType(deficiency),
+		}.Call(page)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// suppressPrintJS turns window.print into a no-op which still fires the beforeprint and afterprint events.
+const suppressPrintJS = `(() => {
+	window.print = function print() {
+		window.dispatchEvent(new Event('beforeprint'));
+		window.dispatchEvent(new Event('afterprint'));
+	};
+})()`
+
+// WithSuppressPrint dismisses window.print calls of the page, which otherwise open a print dialog
+// that blocks the page when the browser isn't headless.
+func WithSuppressPrint() PageOption {
+	return func(page *rod.Page) {
+		page.MustEvalOnNewDocument(suppressPrintJS)
+	}
+}
+
+// SetGeolocation grants the page the geolocation permission for all origins and reports the position as
+// lat and lon, in degrees, within accuracy meters. It applies right away to a page already in use.
+func (b *Browser) SetGeolocation(page *rod.Page, lat, lon, accuracy float64) error {
+	err := proto.BrowserGrantPermissions{
+		Permissions:      []proto.BrowserPermissionType{proto.BrowserPermissionTypeGeolocation},
+		BrowserContextID: page.Browser().BrowserContextID,
+	}.Call(page.Browser())
+	if err != nil {
+		return fmt.Errorf("failed to grant geolocation permission: %w", err)
+	}
+
+	err = proto.EmulationSetGeolocationOverride{
+		Latitude:  &lat,
+		Longitude: &lon,
+		Accuracy:  &accuracy,
+	}.Call(page)
+	if err != nil {
+		return fmt.Errorf("failed to set geolocation: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package browser
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestWithVisionDeficiency(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body style="margin: 0; background: rgb(255, 0, 0)"></body></html>`,
+	})
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage(WithVisionDeficiency("achromatopsia"))
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	data, err := b.ScreenshotFullPage(page)
+	assert.NoError(t, err)
+
+	shot, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	// Without color vision, the red background is rendered as a shade of gray.
+	r, g, bl, _ := shot.At(10, 10).RGBA()
+	assert.InDelta(t, r, g, 0x800)
+	assert.InDelta(t, g, bl, 0x800)
+
+	assert.Panics(t, func() {
+		WithVisionDeficiency("unknown")(page)
+	})
+}
+
+func TestWithSuppressPrint(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body><script>
+			window.addEventListener('afterprint', () => { document.body.dataset.printed = 'yes'; });
+			window.print();
+			document.title = 'after print';
+		</script></body></html>`,
+	})
+
+	b := newTestBrowser(t, WithHeadless(false))
+
+	page, err := b.GetPage(WithSuppressPrint())
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	err = b.Navigate(page, srv.URL, &NavigateOptions{Timeout: 10 * time.Second})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "after print", page.MustInfo().Title)
+	assert.Equal(t, "yes", page.MustEval(`() => document.body.dataset.printed`).Str())
+}
+
+func TestSetGeolocation(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body></body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	position := func() (float64, float64) {
+		res := page.MustEval(`() => new Promise((resolve, reject) => navigator.geolocation.getCurrentPosition(
+			pos => resolve([pos.coords.latitude, pos.coords.longitude]), err => reject(new Error(err.message))))`)
+		return res.Get("0").Num(), res.Get("1").Num()
+	}
+
+	assert.NoError(t, b.SetGeolocation(page, 48.8584, 2.2945, 10))
+	lat, lon := position()
+	assert.Equal(t, 48.8584, lat)
+	assert.Equal(t, 2.2945, lon)
+
+	// The position can change mid-session.
+	assert.NoError(t, b.SetGeolocation(page, 40.6892, -74.0445, 10))
+	lat, lon = position()
+	assert.Equal(t, 40.6892, lat)
+	assert.Equal(t, -74.0445, lon)
+}
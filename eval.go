@@ -0,0 +1,100 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-rod/rod"
+	"os"
+	"sync"
+	"time"
+)
+
+// scripts caches the content of the files evaluated by EvalFile, keyed by path.
+var (
+	scripts   = make(map[string]string)
+	scriptsMu sync.RWMutex
+)
+
+// EvalFile evaluates the JavaScript function stored in the file at path with the args,
+// and decodes its result, awaited if it's a promise, into out. The out can be nil to ignore the result.
+// The file must contain a single function expression, e.g. "(selector) => document.querySelectorAll(selector).length".
+// Files are read once and cached for the lifetime of the process, so changes to a file after its first use are ignored.
+func (b *Browser) EvalFile(page *rod.Page, path string, out interface{}, args ...interface{}) error {
+	js, err := readScript(path)
+	if err != nil {
+		return err
+	}
+
+	res, err := page.Eval(js, args...)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate %s: %w", path, err)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := res.Value.Unmarshal(out); err != nil {
+		return fmt.Errorf("failed to decode result of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readScript returns the content of the file at path, from the cache if it was already read.
+func readScript(path string) (string, error) {
+	scriptsMu.RLock()
+	js, ok := scripts[path]
+	scriptsMu.RUnlock()
+	if ok {
+		return js, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read script: %w", err)
+	}
+
+	scriptsMu.Lock()
+	scripts[path] = string(data)
+	scriptsMu.Unlock()
+
+	return string(data), nil
+}
+
+// Poll evaluates the JavaScript function js on the page every interval, e.g. "() => document.title",
+// and streams its JSON encoded results, awaited if they're promises, until ctx is cancelled or stop is called.
+// The channel is closed once polling stops. Evaluations that fail, e.g. while the page navigates, are skipped.
+// A result is only evaluated once the previous one has been received, so a slow reader doesn't pile results up.
+func (b *Browser) Poll(ctx context.Context, page *rod.Page, js string, interval time.Duration) (<-chan json.RawMessage, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	results := make(chan json.RawMessage)
+
+	go func() {
+		defer close(results)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		p := page.Context(ctx)
+		for {
+			if res, err := p.Eval(js); err == nil {
+				if data, err := res.Value.MarshalJSON(); err == nil {
+					select {
+					case results <- data:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results, cancel
+}
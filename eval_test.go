@@ -0,0 +1,86 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadScriptCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cached.js")
+	assert.NoError(t, os.WriteFile(path, []byte(`() => 1`), 0o644))
+
+	js, err := readScript(path)
+	assert.NoError(t, err)
+	assert.Equal(t, `() => 1`, js)
+
+	// The file is only read once.
+	assert.NoError(t, os.Remove(path))
+	js, err = readScript(path)
+	assert.NoError(t, err)
+	assert.Equal(t, `() => 1`, js)
+
+	_, err = readScript(filepath.Join(t.TempDir(), "missing.js"))
+	assert.Error(t, err)
+}
+
+func TestEvalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sum.js")
+	assert.NoError(t, os.WriteFile(path, []byte(`(a, b) => ({ sum: a + b, title: document.title })`), 0o644))
+
+	srv := newTestServer(t, testPages{
+		"/": `<html><head><title>Eval</title></head></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	var result struct {
+		Sum   int    `json:"sum"`
+		Title string `json:"title"`
+	}
+	assert.NoError(t, b.EvalFile(page, path, &result, 2, 3))
+	assert.Equal(t, 5, result.Sum)
+	assert.Equal(t, "Eval", result.Title)
+
+	assert.NoError(t, b.EvalFile(page, path, nil, 1, 1))
+}
+
+func TestPoll(t *testing.T) {
+	b, page := newTestPage(t)
+
+	results, stop := b.Poll(context.Background(), page, `() => Date.now()`, 50*time.Millisecond)
+
+	var last int64
+	for i := 0; i < 3; i++ {
+		var now int64
+		assert.NoError(t, json.Unmarshal(<-results, &now))
+		assert.Greater(t, now, last)
+		last = now
+	}
+
+	stop()
+	for range results {
+		// Drain until the channel is closed.
+	}
+
+	// Cancelling the context stops polling too.
+	ctx, cancel := context.WithCancel(context.Background())
+	results, stop = b.Poll(ctx, page, `() => 1`, 50*time.Millisecond)
+	defer stop()
+	assert.Equal(t, json.RawMessage(`1`), <-results)
+	cancel()
+	assert.Eventually(t, func() bool {
+		select {
+		case _, ok := <-results:
+			return !ok
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+}
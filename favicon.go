@@ -0,0 +1,42 @@
+package browser
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/go-rod/rod"
+)
+
+// faviconJS fetches the icon declared by the document, or /favicon.ico of its origin,
+// and returns it base64 encoded along with its content type.
+const faviconJS = `async () => {
+	const link = Array.from(document.querySelectorAll('link[rel]'))
+		.find(el => el.rel.toLowerCase().split(/\s+/).includes('icon') && el.href);
+	const url = link ? link.href : new URL('/favicon.ico', location.href).href;
+
+	const res = await fetch(url, { credentials: 'include' });
+	if (!res.ok) throw new Error('failed to fetch ' + url + ': ' + res.status);
+
+	const bytes = new Uint8Array(await res.arrayBuffer());
+	let binary = '';
+	for (let i = 0; i < bytes.length; i += 0x8000) {
+		binary += String.fromCharCode.apply(null, bytes.subarray(i, i + 0x8000));
+	}
+
+	return { data: btoa(binary), contentType: res.headers.get('Content-Type') || '' };
+}`
+
+// Favicon fetches the favicon of the page, the icon declared by a <link rel="icon"> element or /favicon.ico otherwise.
+// It's fetched from within the page, so it's subject to the page's cookies and to CORS for icons of other origins.
+func (b *Browser) Favicon(page *rod.Page) (data []byte, contentType string, err error) {
+	res, err := page.Eval(faviconJS)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch favicon: %w", err)
+	}
+
+	data, err = base64.StdEncoding.DecodeString(res.Value.Get("data").Str())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode favicon: %w", err)
+	}
+
+	return data, res.Value.Get("contentType").Str(), nil
+}
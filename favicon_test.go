@@ -0,0 +1,41 @@
+package browser
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"testing"
+)
+
+func TestFavicon(t *testing.T) {
+	var icon bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	assert.NoError(t, png.Encode(&icon, img))
+
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/icon.png", "/favicon.ico":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(icon.Bytes())
+		case "/declared":
+			_, _ = w.Write([]byte(`<html><head><link rel="shortcut icon" href="/icon.png"></head><body></body></html>`))
+		default:
+			_, _ = w.Write([]byte(`<html><body></body></html>`))
+		}
+	}))
+
+	b, page := newTestPage(t)
+
+	for _, path := range []string{"/declared", "/"} {
+		page.MustNavigate(srv.URL + path).MustWaitLoad()
+
+		data, contentType, err := b.Favicon(page)
+		assert.NoError(t, err)
+		assert.Equal(t, icon.Bytes(), data)
+		assert.Equal(t, "image/png", contentType)
+	}
+}
@@ -0,0 +1,246 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"net/url"
+	"sync"
+)
+
+// pageHijack is the request router of a page.
+// A page only has one router, because enabling the Fetch domain replaces the patterns of any other router,
+// so every handler registered on the page is dispatched by it.
+// Its context is derived from the page's context, so the router stops when the page goes away.
+type pageHijack struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	router   *rod.HijackRouter
+	handlers []*hijackHandler
+	once     sync.Once
+
+	// auth is the credential answering the proxy challenges of the page, see handleProxyAuth.
+	auth *Credential
+	// proxy is the proxy of WithProxyRotationPerPage the page's requests are routed through, see routeThroughProxy.
+	proxy *url.URL
+}
+
+// hijackHandler handles the hijacked requests of a resource type, or of all types if resourceType is empty.
+// A handler that doesn't want to handle a request sets Hijack.Skip to pass it to the next handler.
+// Persistent handlers live as long as the page, the others are removed when the page is put back into the pool.
+// Terminal handlers answer every request they get, so they're dispatched after the others whenever they were added.
+type hijackHandler struct {
+	resourceType proto.NetworkResourceType
+	persistent   bool
+	terminal     bool
+	handle       func(*rod.Hijack)
+}
+
+// stop stops the router and releases its context.
+// The router is stopped before the context is cancelled, so the Fetch domain is disabled on a page that lives on.
+func (h *pageHijack) stop() {
+	h.once.Do(func() {
+		_ = h.router.Stop()
+		h.cancel()
+	})
+}
+
+// hijack registers a handler for the requests of the page and starts the page's router if needed.
+// The handler stops when the returned remove function is called, when the page is closed,
+// or when the browser is closed. Unless it's persistent, it also stops when the page is put back into the pool.
+func (b *Browser) hijack(page *rod.Page, resourceType proto.NetworkResourceType, persistent bool, handle func(*rod.Hijack)) (remove func(), err error) {
+	return b.addHijackHandler(page, &hijackHandler{
+		resourceType: resourceType,
+		persistent:   persistent,
+		handle:       handle,
+	})
+}
+
+// addHijackHandler registers the handler for the requests of the page, like hijack.
+func (b *Browser) addHijackHandler(page *rod.Page, handler *hijackHandler) (remove func(), err error) {
+	b.hijackMu.Lock()
+	defer b.hijackMu.Unlock()
+
+	h, ok := b.hijacks[page.TargetID]
+	if !ok {
+		ctx, cancel := context.WithCancel(page.GetContext())
+		h = &pageHijack{ctx: ctx, cancel: cancel}
+		h.router = page.Context(ctx).HijackRequests()
+		if err := h.router.Add("*", "", func(ctx *rod.Hijack) {
+			b.dispatch(h, ctx)
+		}); err != nil {
+			h.stop()
+			return nil, err
+		}
+
+		if b.hijacks == nil {
+			b.hijacks = make(map[proto.TargetTargetID]*pageHijack)
+		}
+		b.hijacks[page.TargetID] = h
+
+		go h.router.Run()
+		go b.watchPageClose(page, h)
+	}
+
+	h.handlers = append(h.handlers, handler)
+
+	return func() {
+		b.removeHijackHandlers(page.TargetID, func(other *hijackHandler) bool {
+			return other == handler
+		})
+	}, nil
+}
+
+// dispatch passes a hijacked request to the handlers of the page in registration order, terminal handlers last.
+// Requests that no handler wants are continued unchanged.
+func (b *Browser) dispatch(h *pageHijack, ctx *rod.Hijack) {
+	b.hijackMu.Lock()
+	handlers := make([]*hijackHandler, 0, len(h.handlers))
+	var terminal []*hijackHandler
+	for _, handler := range h.handlers {
+		if handler.terminal {
+			terminal = append(terminal, handler)
+		} else {
+			handlers = append(handlers, handler)
+		}
+	}
+	handlers = append(handlers, terminal...)
+	b.hijackMu.Unlock()
+
+	for _, handler := range handlers {
+		if handler.resourceType != "" && handler.resourceType != ctx.Request.Type() {
+			continue
+		}
+
+		handler.handle(ctx)
+		if !ctx.Skip {
+			return
+		}
+		ctx.Skip = false
+	}
+
+	ctx.ContinueRequest(&proto.FetchContinueRequest{})
+}
+
+// watchPageClose stops the router of the page once the page's target is destroyed.
+func (b *Browser) watchPageClose(page *rod.Page, h *pageHijack) {
+	page.Browser().Context(h.ctx).EachEvent(func(e *proto.TargetTargetDestroyed) bool {
+		return e.TargetID == page.TargetID
+	})()
+
+	b.hijackMu.Lock()
+	if b.hijacks[page.TargetID] == h {
+		delete(b.hijacks, page.TargetID)
+	}
+	b.hijackMu.Unlock()
+
+	h.stop()
+}
+
+// removeHijackHandlers removes the handlers of the page selected by remove,
+// and stops the page's router once it has no handlers left.
+func (b *Browser) removeHijackHandlers(id proto.TargetTargetID, remove func(*hijackHandler) bool) {
+	b.hijackMu.Lock()
+	h, ok := b.hijacks[id]
+	if !ok {
+		b.hijackMu.Unlock()
+		return
+	}
+
+	handlers := make([]*hijackHandler, 0, len(h.handlers))
+	for _, handler := range h.handlers {
+		if !remove(handler) {
+			handlers = append(handlers, handler)
+		}
+	}
+	h.handlers = handlers
+
+	if len(handlers) > 0 {
+		b.hijackMu.Unlock()
+		return
+	}
+	delete(b.hijacks, id)
+	b.hijackMu.Unlock()
+
+	h.stop()
+}
+
+// StopHijacking stops the request interception started on the page by helpers such as BlockImageLoading,
+// and stops the page's router once nothing else intercepts its requests. PutPage calls it for pages put back into the pool.
+// Interception the page needs for its whole lifetime, such as the routing of WithProxyRotationPerPage, keeps running.
+func (b *Browser) StopHijacking(page *rod.Page) {
+	b.removeHijackHandlers(page.TargetID, func(handler *hijackHandler) bool {
+		return !handler.persistent
+	})
+}
+
+// stopAllHijacking stops the request routers of every page of the browser.
+func (b *Browser) stopAllHijacking() {
+	b.hijackMu.Lock()
+	hijacks := b.hijacks
+	b.hijacks = nil
+	b.hijackMu.Unlock()
+
+	for _, h := range hijacks {
+		h.stop()
+	}
+}
+
+// handleProxyAuth answers the proxy authentication challenges of the page with the credential, for the page's lifetime.
+// The page's router handles the paused requests, so a persistent handler keeps it running,
+// and the Fetch domain is re-enabled with the router's catch-all pattern plus authentication handling.
+// If the page already answers the challenges, the credential replaces the previous one.
+func (b *Browser) handleProxyAuth(page *rod.Page, cred *Credential) error {
+	b.hijackMu.Lock()
+	if h, ok := b.hijacks[page.TargetID]; ok && h.auth != nil {
+		h.auth = cred
+		b.hijackMu.Unlock()
+		return nil
+	}
+	b.hijackMu.Unlock()
+
+	if _, err := b.hijack(page, "", true, func(ctx *rod.Hijack) {
+		ctx.Skip = true
+	}); err != nil {
+		return err
+	}
+
+	// The page may have been closed in the meantime, which stops its router
+	b.hijackMu.Lock()
+	h, ok := b.hijacks[page.TargetID]
+	if !ok {
+		b.hijackMu.Unlock()
+		return errors.New("page was closed")
+	}
+	h.auth = cred
+	b.hijackMu.Unlock()
+
+	err := proto.FetchEnable{
+		Patterns:           []*proto.FetchRequestPattern{{URLPattern: "*"}},
+		HandleAuthRequests: true,
+	}.Call(page)
+	if err != nil {
+		return err
+	}
+
+	go page.Context(h.ctx).EachEvent(func(e *proto.FetchAuthRequired) {
+		res := &proto.FetchAuthChallengeResponse{
+			Response: proto.FetchAuthChallengeResponseResponseDefault,
+		}
+		if e.AuthChallenge.Source == proto.FetchAuthChallengeSourceProxy {
+			b.hijackMu.Lock()
+			cred := h.auth
+			b.hijackMu.Unlock()
+
+			res = &proto.FetchAuthChallengeResponse{
+				Response: proto.FetchAuthChallengeResponseResponseProvideCredentials,
+				Username: cred.Username,
+				Password: cred.Password,
+			}
+		}
+		_ = proto.FetchContinueWithAuth{RequestID: e.RequestID, AuthChallengeResponse: res}.Call(page)
+	})()
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package browser
+
+import (
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestHijackStopsOnPageClose(t *testing.T) {
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+
+	// Let the goroutines of the page settle before counting.
+	time.Sleep(500 * time.Millisecond)
+	before := runtime.NumGoroutine()
+
+	err = b.BlockImageLoading(page)
+	assert.NoError(t, err)
+
+	b.hijackMu.Lock()
+	assert.Len(t, b.hijacks[page.TargetID].handlers, 1)
+	b.hijackMu.Unlock()
+
+	err = page.Close()
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		b.hijackMu.Lock()
+		defer b.hijackMu.Unlock()
+		_, ok := b.hijacks[page.TargetID]
+		return !ok
+	}, 5*time.Second, 100*time.Millisecond, "The router should be released when the page is closed")
+
+	assert.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= before
+	}, 5*time.Second, 100*time.Millisecond, "The router goroutines should exit when the page is closed")
+}
+
+func TestHijackStopsOnPutPage(t *testing.T) {
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+
+	err = b.BlockImageLoading(page)
+	assert.NoError(t, err)
+
+	b.PutPage(page)
+
+	b.hijackMu.Lock()
+	_, ok := b.hijacks[page.TargetID]
+	b.hijackMu.Unlock()
+	assert.False(t, ok, "The router should be stopped when the page is put back")
+}
+
+func TestBlockResourceTypes(t *testing.T) {
+	images := newImageServer(t)
+	srv := newTestServer(t, testPages{
+		"/": `<html><head><link rel="stylesheet" href="/style.css"></head><body>
+			<img id="img" src="` + images.URL + `/red.png">
+			<script src="/script.js"></script>
+		</body></html>`,
+		"/style.css": `body { color: rgb(0, 0, 255); }`,
+		"/script.js": `window.scriptLoaded = true;`,
+	})
+
+	b, page := newTestPage(t)
+
+	err := b.BlockResourceTypes(page, proto.NetworkResourceTypeImage, proto.NetworkResourceTypeStylesheet)
+	assert.NoError(t, err)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	assert.Equal(t, 0, page.MustEval(`() => document.getElementById('img').naturalWidth`).Int())
+	assert.Equal(t, "rgb(0, 0, 0)", page.MustEval(`() => getComputedStyle(document.body).color`).Str())
+	// Other types load as usual.
+	assert.True(t, page.MustEval(`() => window.scriptLoaded === true`).Bool())
+}
+
+func TestGlobRegexp(t *testing.T) {
+	cases := []struct {
+		pattern string
+		url     string
+		match   bool
+	}{
+		{"*doubleclick.net*", "https://ad.doubleclick.net/ddm/activity", true},
+		{"*DoubleClick.NET*", "https://ad.doubleclick.net/ddm/activity", true},
+		{"*doubleclick.net*", "https://example.com/", false},
+		{"https://*.example.com/ads/*", "https://cdn.example.com/ads/banner.js", true},
+		{"https://*.example.com/ads/*", "https://cdn.example.com/Ads/banner.js", false},
+		{"https://*.EXAMPLE.com/ads/*", "https://cdn.example.com/ads/banner.js", true},
+		{"*/pixel.gif?id=?", "https://t.example.com/pixel.gif?id=1", true},
+		{"*/pixel.gif?id=?", "https://t.example.com/pixel.gif?id=12", false},
+	}
+	for _, c := range cases {
+		u, err := url.Parse(c.url)
+		assert.NoError(t, err)
+		assert.Equal(t, c.match, globRegexp(lowerGlobHost(c.pattern)).MatchString(lowerURLHost(u)), "%s %s", c.pattern, c.url)
+	}
+}
+
+func TestBlockURLPatterns(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/":           `<html><body><script src="/tracker.js"></script><script src="/app.js"></script></body></html>`,
+		"/tracker.js": `window.tracked = true;`,
+		"/app.js":     `window.app = true;`,
+	})
+
+	b, page := newTestPage(t)
+
+	assert.NoError(t, b.BlockURLPatterns(page, "*/tracker.js"))
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	assert.False(t, page.MustEval(`() => window.tracked === true`).Bool())
+	assert.True(t, page.MustEval(`() => window.app === true`).Bool())
+}
+
+func TestStopHijacking(t *testing.T) {
+	b, page := newTestPage(t)
+
+	// Let the goroutines of the page settle before counting.
+	time.Sleep(500 * time.Millisecond)
+	before := runtime.NumGoroutine()
+
+	assert.NoError(t, b.BlockImageLoading(page))
+	assert.NoError(t, b.BlockURLPatterns(page, "*tracker*"))
+
+	b.StopHijacking(page)
+
+	b.hijackMu.Lock()
+	_, ok := b.hijacks[page.TargetID]
+	b.hijackMu.Unlock()
+	assert.False(t, ok, "The router should be stopped")
+
+	assert.Eventually(t, func() bool {
+		return runtime.NumGoroutine() <= before
+	}, 5*time.Second, 100*time.Millisecond, "The router goroutines should exit")
+}
@@ -0,0 +1,247 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"math/rand"
+	"path/filepath"
+	"time"
+)
+
+// DropFiles drops the files onto the element matching the selector, as if they were dragged from the desktop.
+// It dispatches a drag-and-drop sequence carrying the files at the center of the element,
+// which is what upload widgets that only listen for drop events expect.
+// If the element is a file input, its files are set directly instead.
+func (b *Browser) DropFiles(page *rod.Page, selector string, files ...string) error {
+	if len(files) == 0 {
+		return errors.New("no files to drop")
+	}
+
+	paths := make([]string, len(files))
+	for i, file := range files {
+		path, err := filepath.Abs(file)
+		if err != nil {
+			return fmt.Errorf("failed to resolve file %s: %w", file, err)
+		}
+		paths[i] = path
+	}
+
+	el, err := page.Element(selector)
+	if err != nil {
+		return fmt.Errorf("failed to find drop target %s: %w", selector, err)
+	}
+
+	isFileInput, err := el.Eval(`() => this instanceof HTMLInputElement && this.type === 'file'`)
+	if err != nil {
+		return fmt.Errorf("failed to inspect drop target %s: %w", selector, err)
+	}
+	if isFileInput.Value.Bool() {
+		if err := el.SetFiles(paths); err != nil {
+			return fmt.Errorf("failed to set files on %s: %w", selector, err)
+		}
+		return nil
+	}
+
+	if err := el.ScrollIntoView(); err != nil {
+		return fmt.Errorf("failed to scroll to drop target %s: %w", selector, err)
+	}
+
+	shape, err := el.Shape()
+	if err != nil {
+		return fmt.Errorf("failed to get the position of drop target %s: %w", selector, err)
+	}
+	box := shape.Box()
+	if box == nil {
+		return fmt.Errorf("drop target %s is not visible", selector)
+	}
+
+	data := &proto.InputDragData{
+		Items:              []*proto.InputDragDataItem{},
+		Files:              paths,
+		DragOperationsMask: 1, // Copy
+	}
+
+	for _, eventType := range []proto.InputDispatchDragEventType{
+		proto.InputDispatchDragEventTypeDragEnter,
+		proto.InputDispatchDragEventTypeDragOver,
+		proto.InputDispatchDragEventTypeDrop,
+	} {
+		err := proto.InputDispatchDragEvent{
+			Type: eventType,
+			X:    box.X + box.Width/2,
+			Y:    box.Y + box.Height/2,
+			Data: data,
+		}.Call(page)
+		if err != nil {
+			return fmt.Errorf("failed to dispatch %s event: %w", eventType, err)
+		}
+	}
+
+	return nil
+}
+
+// Typing delays between two keystrokes of TypeHuman.
+const (
+	minTypingDelay = 40 * time.Millisecond
+	maxTypingDelay = 160 * time.Millisecond
+)
+
+// TypeHuman types the text into the element matching the selector one key at a time, with a random delay between keys.
+// The element is focused and the caret moved to the end of its content, then each character is sent as
+// real key events, so it works for inputs, textareas and contenteditable rich editors alike,
+// and the page sees the same keydown, input and keyup events as for a person typing.
+// Characters that have no key on a US keyboard are inserted as text input.
+func (b *Browser) TypeHuman(page *rod.Page, selector, text string) error {
+	el, err := page.Element(selector)
+	if err != nil {
+		return fmt.Errorf("failed to find element %s: %w", selector, err)
+	}
+
+	if err := el.Focus(); err != nil {
+		return fmt.Errorf("failed to focus element %s: %w", selector, err)
+	}
+
+	_, err = el.Eval(`() => {
+		if (this.isContentEditable) {
+			const range = document.createRange();
+			range.selectNodeContents(this);
+			range.collapse(false);
+			const selection = window.getSelection();
+			selection.removeAllRanges();
+			selection.addRange(range);
+		} else if (typeof this.setSelectionRange === 'function' && typeof this.value === 'string') {
+			try { this.setSelectionRange(this.value.length, this.value.length); } catch (e) {}
+		}
+	}`)
+	if err != nil {
+		return fmt.Errorf("failed to move caret in element %s: %w", selector, err)
+	}
+
+	for i, r := range text {
+		if i > 0 {
+			time.Sleep(minTypingDelay + time.Duration(rand.Int63n(int64(maxTypingDelay-minTypingDelay))))
+		}
+
+		if key, ok := typingKey(r); ok {
+			err = page.Keyboard.Type(key)
+		} else {
+			err = page.InsertText(string(r))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to type into element %s: %w", selector, err)
+		}
+	}
+
+	return nil
+}
+
+// TypeAndWaitSuggestions types the text into the input matching inputSelector like TypeHuman,
+// then waits until elements matching suggestionSelector appear, such as the entries of an autosuggest list,
+// and returns them. It returns an error if no suggestion appears within the timeout.
+func (b *Browser) TypeAndWaitSuggestions(page *rod.Page, inputSelector, text, suggestionSelector string, timeout time.Duration) ([]*rod.Element, error) {
+	if err := b.TypeHuman(page, inputSelector, text); err != nil {
+		return nil, err
+	}
+
+	p := page.Timeout(timeout)
+	defer p.CancelTimeout()
+
+	if err := p.Wait(rod.Eval(`sel => document.querySelectorAll(sel).length > 0`, suggestionSelector)); err != nil {
+		return nil, fmt.Errorf("failed to wait for suggestions %s: %w", suggestionSelector, err)
+	}
+
+	suggestions, err := page.Elements(suggestionSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find suggestions %s: %w", suggestionSelector, err)
+	}
+
+	return suggestions, nil
+}
+
+// typingKey returns the keyboard key that types the character, if there is one.
+func typingKey(r rune) (input.Key, bool) {
+	switch {
+	case r == '\n' || r == '\r':
+		return input.Enter, true
+	case r == '\t':
+		return input.Tab, true
+	case r >= ' ' && r <= '~':
+		return input.Key(r), true
+	default:
+		return 0, false
+	}
+}
+
+// Mouse path settings of HumanMouseMove.
+const (
+	minMouseSteps = 20
+	maxMouseSteps = 40
+	minMouseDelay = 5 * time.Millisecond
+	maxMouseDelay = 20 * time.Millisecond
+)
+
+// HumanMouseMove moves the mouse from its current position to the element matching the selector
+// along a curved path, pausing briefly between the intermediate points, so the page sees a stream of
+// mousemove events like for a person moving the mouse. The path ends at a random point near the center of the element.
+func (b *Browser) HumanMouseMove(page *rod.Page, toSelector string) error {
+	el, err := page.Element(toSelector)
+	if err != nil {
+		return fmt.Errorf("failed to find element %s: %w", toSelector, err)
+	}
+
+	if err := el.ScrollIntoView(); err != nil {
+		return fmt.Errorf("failed to scroll to element %s: %w", toSelector, err)
+	}
+
+	shape, err := el.Shape()
+	if err != nil {
+		return fmt.Errorf("failed to get the position of element %s: %w", toSelector, err)
+	}
+	box := shape.Box()
+	if box == nil {
+		return fmt.Errorf("element %s is not visible", toSelector)
+	}
+
+	from := page.Mouse.Position()
+	to := proto.Point{
+		X: box.X + box.Width/2 + (rand.Float64()-0.5)*box.Width/2,
+		Y: box.Y + box.Height/2 + (rand.Float64()-0.5)*box.Height/2,
+	}
+
+	// Bend the path by a control point off the straight line, on a random side
+	dx, dy := to.X-from.X, to.Y-from.Y
+	bend := (rand.Float64() - 0.5) * 0.6
+	ctrl := proto.Point{
+		X: from.X + dx/2 - dy*bend,
+		Y: from.Y + dy/2 + dx*bend,
+	}
+
+	steps := minMouseSteps + rand.Intn(maxMouseSteps-minMouseSteps+1)
+	for _, p := range curvePath(from, ctrl, to, steps) {
+		if err := page.Mouse.MoveTo(p); err != nil {
+			return fmt.Errorf("failed to move mouse to element %s: %w", toSelector, err)
+		}
+		time.Sleep(minMouseDelay + time.Duration(rand.Int63n(int64(maxMouseDelay-minMouseDelay))))
+	}
+
+	return nil
+}
+
+// curvePath returns the points of the quadratic Bézier curve from "from" to "to" bent towards ctrl,
+// excluding the starting point and ending exactly at "to".
+func curvePath(from, ctrl, to proto.Point, steps int) []proto.Point {
+	points := make([]proto.Point, steps)
+	for i := range points {
+		t := float64(i+1) / float64(steps)
+		u := 1 - t
+		points[i] = proto.Point{
+			X: u*u*from.X + 2*u*t*ctrl.X + t*t*to.X,
+			Y: u*u*from.Y + 2*u*t*ctrl.Y + t*t*to.Y,
+		}
+	}
+
+	return points
+}
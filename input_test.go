@@ -0,0 +1,155 @@
+package browser
+
+import (
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDropFiles(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body>
+			<div id="dropzone" style="width: 300px; height: 200px; border: 1px dashed">Drop here</div>
+			<script>
+				const zone = document.getElementById('dropzone');
+				zone.addEventListener('dragover', e => e.preventDefault());
+				zone.addEventListener('drop', e => {
+					e.preventDefault();
+					window.dropped = Array.from(e.dataTransfer.files).map(f => f.name);
+				});
+			</script>
+		</body></html>`,
+	})
+
+	file := filepath.Join(t.TempDir(), "upload.txt")
+	assert.NoError(t, os.WriteFile(file, []byte("hello"), 0o644))
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL)
+	page.MustWaitLoad()
+
+	err := b.DropFiles(page, "#dropzone", file)
+	assert.NoError(t, err)
+
+	err = page.Timeout(5 * time.Second).Wait(rod.Eval(`() => Array.isArray(window.dropped)`))
+	assert.NoError(t, err)
+	assert.Equal(t, "upload.txt", page.MustEval(`() => window.dropped[0]`).String())
+}
+
+func TestTypingKey(t *testing.T) {
+	for _, r := range "az AZ09!@#~`\\" {
+		key, ok := typingKey(r)
+		assert.True(t, ok)
+		assert.NotPanics(t, func() { key.Info() })
+	}
+
+	key, ok := typingKey('\n')
+	assert.True(t, ok)
+	assert.Equal(t, input.Enter, key)
+
+	_, ok = typingKey('é')
+	assert.False(t, ok)
+}
+
+func TestTypeHuman(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body>
+			<input id="input" value="Hi ">
+			<div id="editor" contenteditable="true">Hello </div>
+			<script>
+				window.inputEvents = 0;
+				document.getElementById('editor').addEventListener('input', () => window.inputEvents++);
+			</script>
+		</body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	assert.NoError(t, b.TypeHuman(page, "#input", "there!"))
+	assert.Equal(t, "Hi there!", page.MustElement("#input").MustProperty("value").String())
+
+	assert.NoError(t, b.TypeHuman(page, "#editor", "wörld"))
+	assert.Equal(t, "Hello wörld", page.MustElement("#editor").MustText())
+	assert.Equal(t, 5, page.MustEval(`() => window.inputEvents`).Int())
+}
+
+func TestTypeAndWaitSuggestions(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body>
+			<input id="search">
+			<ul id="suggestions"></ul>
+			<script>
+				const words = ['golang', 'gopher', 'google', 'rust'];
+				let timer;
+				document.getElementById('search').addEventListener('input', e => {
+					clearTimeout(timer);
+					timer = setTimeout(() => {
+						const q = e.target.value;
+						document.getElementById('suggestions').innerHTML = words
+							.filter(w => w.startsWith(q))
+							.map(w => '<li class="suggestion">' + w + '</li>')
+							.join('');
+					}, 300);
+				});
+			</script>
+		</body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	suggestions, err := b.TypeAndWaitSuggestions(page, "#search", "go", ".suggestion", 5*time.Second)
+	assert.NoError(t, err)
+	var words []string
+	for _, el := range suggestions {
+		words = append(words, el.MustText())
+	}
+	assert.Equal(t, []string{"golang", "gopher", "google"}, words)
+
+	// Nothing matches, so no suggestion shows up.
+	page.MustNavigate(srv.URL).MustWaitLoad()
+	_, err = b.TypeAndWaitSuggestions(page, "#search", "xyz", ".suggestion", time.Second)
+	assert.Error(t, err)
+}
+
+func TestCurvePath(t *testing.T) {
+	from := proto.Point{X: 0, Y: 0}
+	to := proto.Point{X: 100, Y: 0}
+
+	points := curvePath(from, proto.Point{X: 50, Y: 50}, to, 10)
+	assert.Len(t, points, 10)
+	assert.Equal(t, to, points[len(points)-1])
+	// The path bends towards the control point.
+	assert.Greater(t, points[4].Y, 0.0)
+}
+
+func TestHumanMouseMove(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body style="margin: 0">
+			<button id="target" style="position: absolute; left: 400px; top: 300px; width: 80px; height: 30px">Go</button>
+			<script>
+				window.moves = 0;
+				document.addEventListener('mousemove', () => window.moves++);
+			</script>
+		</body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	assert.NoError(t, b.HumanMouseMove(page, "#target"))
+	assert.GreaterOrEqual(t, page.MustEval(`() => window.moves`).Int(), minMouseSteps)
+
+	hovered := page.MustEval(`() => document.querySelector('#target').matches(':hover')`).Bool()
+	assert.True(t, hovered)
+}
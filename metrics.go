@@ -0,0 +1,62 @@
+package browser
+
+// MetricsCollector receives the lifecycle events of a browser and its page pool, see WithMetrics.
+// Each event comes with the label of the browser set by WithLabel, empty if it has none,
+// so a collector shared by several browsers can tell them apart.
+// The methods are called synchronously, so they should return quickly.
+type MetricsCollector interface {
+	// PageAcquired is called when GetPage hands a page out.
+	PageAcquired(label string)
+	// PageReleased is called when PutPage takes a page back.
+	PageReleased(label string)
+	// BrowserLaunched is called when the browser process is launched, including relaunches.
+	BrowserLaunched(label string)
+	// BrowserClosed is called when the browser is closed, or evicted by the reaper.
+	BrowserClosed(label string)
+}
+
+// WithMetrics reports the lifecycle events of the browser and its page pool to the collector.
+// A nil collector discards the events.
+func WithMetrics(collector MetricsCollector) Option {
+	return func(b *Browser) {
+		if collector == nil {
+			collector = nopMetrics{}
+		}
+		b.metrics = collector
+	}
+}
+
+// nopMetrics is the MetricsCollector used when WithMetrics isn't set.
+type nopMetrics struct{}
+
+func (nopMetrics) PageAcquired(string)    {}
+func (nopMetrics) PageReleased(string)    {}
+func (nopMetrics) BrowserLaunched(string) {}
+func (nopMetrics) BrowserClosed(string)   {}
+
+// MetricsFuncs adapts plain functions to a MetricsCollector, nil functions are skipped.
+// It bridges the events to any metrics library without this package depending on it,
+// e.g. with Prometheus counters partitioned by the label of the browser:
+//
+//	browser.WithMetrics(browser.MetricsFuncs{
+//		OnPageAcquired: func(label string) { pagesAcquired.WithLabelValues(label).Inc() },
+//		OnPageReleased: func(label string) { pagesReleased.WithLabelValues(label).Inc() },
+//	})
+type MetricsFuncs struct {
+	OnPageAcquired    func(label string)
+	OnPageReleased    func(label string)
+	OnBrowserLaunched func(label string)
+	OnBrowserClosed   func(label string)
+}
+
+func (m MetricsFuncs) PageAcquired(label string)    { callIfSet(m.OnPageAcquired, label) }
+func (m MetricsFuncs) PageReleased(label string)    { callIfSet(m.OnPageReleased, label) }
+func (m MetricsFuncs) BrowserLaunched(label string) { callIfSet(m.OnBrowserLaunched, label) }
+func (m MetricsFuncs) BrowserClosed(label string)   { callIfSet(m.OnBrowserClosed, label) }
+
+// callIfSet calls f with the label if it's not nil.
+func callIfSet(f func(label string), label string) {
+	if f != nil {
+		f(label)
+	}
+}
@@ -0,0 +1,69 @@
+package browser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+)
+
+// countingMetrics counts the events it receives, and keeps the label of the last one.
+type countingMetrics struct {
+	acquired, released, launched, closed atomic.Int32
+	label                                atomic.Value
+}
+
+func (m *countingMetrics) PageAcquired(label string)    { m.acquired.Add(1); m.label.Store(label) }
+func (m *countingMetrics) PageReleased(label string)    { m.released.Add(1); m.label.Store(label) }
+func (m *countingMetrics) BrowserLaunched(label string) { m.launched.Add(1); m.label.Store(label) }
+func (m *countingMetrics) BrowserClosed(label string)   { m.closed.Add(1); m.label.Store(label) }
+
+func TestMetricsFuncs(t *testing.T) {
+	acquired := map[string]int{}
+	m := MetricsFuncs{OnPageAcquired: func(label string) { acquired[label]++ }}
+
+	m.PageAcquired("crawler")
+	assert.Equal(t, map[string]int{"crawler": 1}, acquired)
+
+	// Unset functions are skipped.
+	assert.NotPanics(t, func() {
+		m.PageReleased("crawler")
+		m.BrowserLaunched("crawler")
+		m.BrowserClosed("crawler")
+	})
+
+	assert.Equal(t, generateKey(), generateKey(WithMetrics(m)))
+
+	// A nil collector discards the events.
+	b := newDefaultBrowser()
+	WithMetrics(nil)(b)
+	assert.NotPanics(t, func() {
+		b.metrics.PageAcquired(b.label)
+	})
+}
+
+func TestWithMetrics(t *testing.T) {
+	metrics := &countingMetrics{}
+
+	b := newTestBrowser(t, WithMetrics(metrics), WithLabel("crawler"))
+	assert.Equal(t, int32(1), metrics.launched.Load())
+	assert.Equal(t, "crawler", metrics.label.Load())
+
+	page1, err := b.GetPage()
+	assert.NoError(t, err)
+	page2, err := b.GetPage()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), metrics.acquired.Load())
+
+	b.PutPage(page1)
+	b.PutPage(page2)
+	assert.Equal(t, int32(2), metrics.released.Load())
+
+	assert.NoError(t, b.Close())
+	assert.Equal(t, int32(1), metrics.closed.Load())
+
+	// A relaunch is reported too.
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	b.PutPage(page)
+	assert.Equal(t, int32(2), metrics.launched.Load())
+}
@@ -0,0 +1,354 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NavigateOptions configures a navigation made by Navigate.
+type NavigateOptions struct {
+	// Timeout bounds the navigation and the wait for the page to load.
+	// Only this navigation is affected, other operations on the page are left unbounded.
+	// When retrying, each attempt gets its own timeout.
+	// Zero means no timeout.
+	Timeout time.Duration
+
+	// RetryStatuses are the HTTP statuses of the main document that make Navigate try again, e.g. 429 and 503.
+	// Retrying relies on the response of the document, so it's only supported for http and https urls.
+	RetryStatuses []int
+	// MaxRetries is the number of retries before giving up, 3 by default.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled after each retry, 1 second by default.
+	// A Retry-After header in the response takes precedence.
+	RetryBackoff time.Duration
+}
+
+// Navigate navigates the page to the url and waits for it to load.
+// The opts can be nil to use the default options.
+func (b *Browser) Navigate(page *rod.Page, url string, opts *NavigateOptions) error {
+	if opts == nil {
+		opts = &NavigateOptions{}
+	}
+
+	if len(opts.RetryStatuses) == 0 {
+		_, err := b.navigate(page, url, opts.Timeout, false)
+		return err
+	}
+
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	backoff := opts.RetryBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		res, err := b.navigate(page, url, opts.Timeout, true)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(opts.RetryStatuses, res.Status) {
+			return nil
+		}
+		if attempt >= maxRetries {
+			return fmt.Errorf("failed to navigate to %s: status %d after %d retries", url, res.Status, maxRetries)
+		}
+
+		wait := backoff
+		if retryAfter, ok := parseRetryAfter(responseHeader(res, "Retry-After")); ok {
+			wait = retryAfter
+		}
+		backoff *= 2
+
+		select {
+		case <-time.After(wait):
+		case <-page.GetContext().Done():
+			return fmt.Errorf("failed to navigate to %s: %w", url, page.GetContext().Err())
+		}
+	}
+}
+
+// NavigateWithRetry navigates the page to the url and waits for it to load, trying again when the navigation fails,
+// e.g. because the connection was reset, up to attempts times in total. The wait before the first retry is backoff,
+// doubled after each retry. It returns the error of the last attempt if they all fail.
+// Unlike the RetryStatuses of Navigate, a page served with an error status isn't a failure.
+func (b *Browser) NavigateWithRetry(page *rod.Page, url string, attempts int, backoff time.Duration) error {
+	attempts = max(attempts, 1)
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-page.GetContext().Done():
+				return fmt.Errorf("failed to navigate to %s: %w", url, page.GetContext().Err())
+			}
+			backoff *= 2
+		}
+
+		if _, err = b.navigate(page, url, 0, false); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed after %d attempts: %w", attempts, err)
+}
+
+// navigate makes a single navigation attempt for Navigate.
+// If withResponse is true, it waits for the response of the main document and returns it.
+func (b *Browser) navigate(page *rod.Page, url string, timeout time.Duration, withResponse bool) (*proto.NetworkResponse, error) {
+	p := page
+	if timeout > 0 {
+		p = page.Timeout(timeout)
+		defer p.CancelTimeout()
+	}
+
+	// Stop listening for the response if the navigation fails before it arrives
+	ctx, cancel := context.WithCancel(p.GetContext())
+	defer cancel()
+
+	var (
+		res          *proto.NetworkResponse
+		waitResponse func()
+	)
+	if withResponse {
+		// Enable the Network domain on the page's own context, so it's restored even if the listener is cancelled
+		defer page.EnableDomain(&proto.NetworkEnable{})()
+		waitResponse = p.Context(ctx).EachEvent(func(e *proto.NetworkResponseReceived) bool {
+			if e.Type == proto.NetworkResourceTypeDocument && e.FrameID == page.FrameID {
+				res = e.Response
+				return true
+			}
+			return false
+		})
+	}
+
+	if err := p.Navigate(url); err != nil {
+		return nil, fmt.Errorf("failed to navigate to %s: %w", url, err)
+	}
+
+	if withResponse {
+		waitResponse()
+		if res == nil {
+			return nil, fmt.Errorf("failed to get response of %s: %w", url, p.GetContext().Err())
+		}
+	}
+
+	if err := p.WaitLoad(); err != nil {
+		return nil, fmt.Errorf("failed to wait for %s to load: %w", url, err)
+	}
+
+	return res, nil
+}
+
+// RedirectType is how a hop of a redirect chain redirected to the next one.
+type RedirectType string
+
+const (
+	// RedirectHTTP is an HTTP 3xx redirect.
+	RedirectHTTP RedirectType = "http"
+	// RedirectMetaRefresh is a <meta http-equiv="refresh"> tag.
+	RedirectMetaRefresh RedirectType = "meta-refresh"
+	// RedirectHeaderRefresh is a Refresh header.
+	RedirectHeaderRefresh RedirectType = "header-refresh"
+	// RedirectScript is a navigation started by a script, e.g. by setting location.href.
+	RedirectScript RedirectType = "script"
+)
+
+// clientRedirectTypes maps the reasons of client-side navigations to the redirect types.
+var clientRedirectTypes = map[proto.PageClientNavigationReason]RedirectType{
+	proto.PageClientNavigationReasonMetaTagRefresh:    RedirectMetaRefresh,
+	proto.PageClientNavigationReasonHTTPHeaderRefresh: RedirectHeaderRefresh,
+	proto.PageClientNavigationReasonScriptInitiated:   RedirectScript,
+}
+
+// clientRedirectWindow is how long NavigateTrackRedirects waits after a page loads for a client-side redirect.
+const clientRedirectWindow = time.Second
+
+// RedirectHop is a response of a redirect chain.
+type RedirectHop struct {
+	URL    string
+	Status int
+	// Type is how the response redirected to the next hop, empty for the last hop.
+	Type RedirectType
+}
+
+// NavigateTrackRedirects navigates the page to the url, waits for it to load, and returns the redirect chain,
+// from the response of the url to the final response, so a url that redirects twice gives three hops.
+// Both HTTP redirects and client-side redirects, by meta refresh, Refresh header or script, are followed,
+// as long as the client-side ones start within a second after the page loads, which the call waits for.
+// If maxRedirects is positive and the chain has more redirects, an error is returned along with the chain.
+// Only http and https urls are supported.
+func (b *Browser) NavigateTrackRedirects(page *rod.Page, url string, maxRedirects int) (chain []RedirectHop, err error) {
+	ctx, cancel := context.WithCancel(page.GetContext())
+	defer cancel()
+
+	// Loads are reported as true, navigations requested by the page as false
+	activity := make(chan bool)
+	report := func(loaded bool) {
+		select {
+		case activity <- loaded:
+		case <-ctx.Done():
+		}
+	}
+
+	wait := page.Context(ctx).EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		if e.Type == proto.NetworkResourceTypeDocument && e.FrameID == page.FrameID && e.RedirectResponse != nil {
+			chain = append(chain, RedirectHop{URL: e.RedirectResponse.URL, Status: e.RedirectResponse.Status, Type: RedirectHTTP})
+		}
+	}, func(e *proto.NetworkResponseReceived) {
+		if e.Type == proto.NetworkResourceTypeDocument && e.FrameID == page.FrameID {
+			chain = append(chain, RedirectHop{URL: e.Response.URL, Status: e.Response.Status})
+		}
+	}, func(e *proto.PageFrameRequestedNavigation) {
+		if e.FrameID != page.FrameID {
+			return
+		}
+		if t, ok := clientRedirectTypes[e.Reason]; ok && len(chain) > 0 {
+			chain[len(chain)-1].Type = t
+		}
+		report(false)
+	}, func(e *proto.PageLoadEventFired) {
+		report(true)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait()
+	}()
+	stop := func() {
+		cancel()
+		<-done
+	}
+
+	if err := page.Navigate(url); err != nil {
+		stop()
+		return nil, fmt.Errorf("failed to navigate to %s: %w", url, err)
+	}
+
+	// Wait until a page loads and doesn't redirect within the window
+	var quiet <-chan time.Time
+	for settled := false; !settled; {
+		select {
+		case loaded := <-activity:
+			quiet = nil
+			if loaded {
+				quiet = time.After(clientRedirectWindow)
+			}
+		case <-quiet:
+			settled = true
+		case <-ctx.Done():
+			stop()
+			return nil, fmt.Errorf("failed to wait for %s to load: %w", url, page.GetContext().Err())
+		}
+	}
+	stop()
+
+	if maxRedirects > 0 && len(chain)-1 > maxRedirects {
+		return chain, fmt.Errorf("navigation to %s exceeded %d redirects", url, maxRedirects)
+	}
+
+	return chain, nil
+}
+
+// responseHeader returns the value of the response header, looked up case-insensitively.
+func responseHeader(res *proto.NetworkResponse, name string) string {
+	for key, value := range res.Headers {
+		if strings.EqualFold(key, name) {
+			return value.Str()
+		}
+	}
+	return ""
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// NavigatePost navigates the page to the url with a POST request carrying the body, and waits for it to load.
+// Browsers only navigate with GET, so the navigation request is intercepted and its method, body
+// and Content-Type header are replaced before it's sent.
+func (b *Browser) NavigatePost(page *rod.Page, url string, contentType string, body []byte) error {
+	return b.navigateHijacked(page, url, func(ctx *rod.Hijack) {
+		headers := requestHeaders(ctx, "Content-Type")
+		headers = append(headers, &proto.FetchHeaderEntry{Name: "Content-Type", Value: contentType})
+
+		ctx.ContinueRequest(&proto.FetchContinueRequest{
+			Method:   http.MethodPost,
+			PostData: body,
+			Headers:  headers,
+		})
+	})
+}
+
+// NavigateWithCookieHeader navigates the page to the url sending the raw Cookie header, e.g. "session=abc; theme=dark",
+// and waits for it to load. The header replaces the cookies of the navigation request only,
+// nothing is stored in the cookie jar and later requests carry the page's own cookies.
+func (b *Browser) NavigateWithCookieHeader(page *rod.Page, url, cookieHeader string) error {
+	return b.navigateHijacked(page, url, func(ctx *rod.Hijack) {
+		headers := requestHeaders(ctx, "Cookie")
+		headers = append(headers, &proto.FetchHeaderEntry{Name: "Cookie", Value: cookieHeader})
+
+		ctx.ContinueRequest(&proto.FetchContinueRequest{Headers: headers})
+	})
+}
+
+// navigateHijacked navigates the page to the url like Navigate, letting modify handle the navigation request.
+// Only the first document request is passed to modify, the requests that follow, such as redirects, are left alone.
+func (b *Browser) navigateHijacked(page *rod.Page, url string, modify func(ctx *rod.Hijack)) error {
+	var once sync.Once
+	remove, err := b.hijack(page, proto.NetworkResourceTypeDocument, false, func(ctx *rod.Hijack) {
+		handled := false
+		once.Do(func() {
+			handled = true
+			modify(ctx)
+		})
+		ctx.Skip = !handled
+	})
+	if err != nil {
+		return fmt.Errorf("failed to intercept navigation to %s: %w", url, err)
+	}
+	defer remove()
+
+	return b.Navigate(page, url, nil)
+}
+
+// requestHeaders returns the headers of the hijacked request as Fetch header entries, without the excluded ones.
+func requestHeaders(ctx *rod.Hijack, exclude ...string) []*proto.FetchHeaderEntry {
+	var headers []*proto.FetchHeaderEntry
+	for name, value := range ctx.Request.Headers() {
+		excluded := false
+		for _, e := range exclude {
+			if strings.EqualFold(name, e) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			headers = append(headers, &proto.FetchHeaderEntry{Name: name, Value: value.Str()})
+		}
+	}
+
+	return headers
+}
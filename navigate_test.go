@@ -0,0 +1,218 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"html"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNavigateTimeout(t *testing.T) {
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-time.After(5 * time.Second):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Fast</title></head></html>`))
+	}))
+
+	b, page := newTestPage(t)
+
+	err := b.Navigate(page, srv.URL+"/slow", &NavigateOptions{Timeout: time.Second})
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// The timeout only applies to the navigation, the page itself stays usable.
+	err = b.Navigate(page, srv.URL+"/fast", &NavigateOptions{Timeout: 5 * time.Second})
+	assert.NoError(t, err)
+	assert.Equal(t, "Fast", page.MustInfo().Title)
+}
+
+func TestNavigatePost(t *testing.T) {
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprintf(w, `<html><body><p id="method">%s</p><p id="type">%s</p><p id="body">%s</p></body></html>`,
+			r.Method, html.EscapeString(r.Header.Get("Content-Type")), html.EscapeString(string(body)))
+	}))
+
+	b, page := newTestPage(t)
+
+	err := b.NavigatePost(page, srv.URL+"/post", "application/x-www-form-urlencoded", []byte("name=widget&count=2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", page.MustElement("#method").MustText())
+	assert.Equal(t, "application/x-www-form-urlencoded", page.MustElement("#type").MustText())
+	assert.Equal(t, "name=widget&count=2", page.MustElement("#body").MustText())
+
+	// Later navigations are sent unchanged.
+	err = b.Navigate(page, srv.URL+"/get", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", page.MustElement("#method").MustText())
+}
+
+func TestNavigateWithCookieHeader(t *testing.T) {
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprintf(w, `<html><body><p id="cookie">%s</p></body></html>`, html.EscapeString(r.Header.Get("Cookie")))
+	}))
+
+	b, page := newTestPage(t)
+
+	err := b.NavigateWithCookieHeader(page, srv.URL+"/echo", "session=abc; theme=dark")
+	assert.NoError(t, err)
+	assert.Equal(t, "session=abc; theme=dark", page.MustElement("#cookie").MustText())
+
+	// The cookies aren't stored.
+	cookies, err := b.GetCookies(page)
+	assert.NoError(t, err)
+	assert.Empty(t, cookies)
+
+	err = b.Navigate(page, srv.URL+"/echo", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, page.MustElement("#cookie").MustText())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	d, ok := parseRetryAfter("2")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, d)
+
+	d, ok = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour, d, float64(2*time.Second))
+
+	d, ok = parseRetryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Zero(t, d)
+
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestNavigateRetry(t *testing.T) {
+	var attempts atomic.Int32
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if attempts.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`<html><head><title>Unavailable</title></head></html>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Available</title></head></html>`))
+	}))
+
+	b, page := newTestPage(t)
+
+	start := time.Now()
+	err := b.Navigate(page, srv.URL, &NavigateOptions{
+		Timeout:       5 * time.Second,
+		RetryStatuses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		RetryBackoff:  time.Minute,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Available", page.MustInfo().Title)
+	assert.Equal(t, int32(3), attempts.Load())
+	assert.Less(t, time.Since(start), 30*time.Second, "Retry-After should take precedence over the backoff")
+
+	attempts.Store(0)
+	err = b.Navigate(page, srv.URL, &NavigateOptions{
+		RetryStatuses: []int{http.StatusServiceUnavailable},
+		MaxRetries:    1,
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestNavigateTrackRedirects(t *testing.T) {
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusFound)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusMovedPermanently)
+		default:
+			_, _ = w.Write([]byte(`<html><body>done</body></html>`))
+		}
+	}))
+
+	b, page := newTestPage(t)
+
+	chain, err := b.NavigateTrackRedirects(page, srv.URL+"/a", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []RedirectHop{
+		{URL: srv.URL + "/a", Status: http.StatusFound, Type: RedirectHTTP},
+		{URL: srv.URL + "/b", Status: http.StatusMovedPermanently, Type: RedirectHTTP},
+		{URL: srv.URL + "/c", Status: http.StatusOK},
+	}, chain)
+
+	// The chain is still returned when it exceeds the max.
+	chain, err = b.NavigateTrackRedirects(page, srv.URL+"/a", 1)
+	assert.Error(t, err)
+	assert.Len(t, chain, 3)
+}
+
+func TestNavigateTrackClientRedirects(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/script": `<html><body><script>location.href = '/meta';</script></body></html>`,
+		"/meta":   `<html><head><meta http-equiv="refresh" content="0; url=/final"></head><body></body></html>`,
+		"/final":  `<html><body>done</body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	chain, err := b.NavigateTrackRedirects(page, srv.URL+"/script", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []RedirectHop{
+		{URL: srv.URL + "/script", Status: http.StatusOK, Type: RedirectScript},
+		{URL: srv.URL + "/meta", Status: http.StatusOK, Type: RedirectMetaRefresh},
+		{URL: srv.URL + "/final", Status: http.StatusOK},
+	}, chain)
+}
+
+func TestNavigateWithRetry(t *testing.T) {
+	var requests atomic.Int32
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/favicon.ico" {
+			http.NotFound(w, r)
+			return
+		}
+		// Drop the connection of the first two requests without a response.
+		if requests.Add(1) <= 2 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+
+	b, page := newTestPage(t)
+
+	// Two attempts aren't enough.
+	err := b.NavigateWithRetry(page, srv.URL, 2, 10*time.Millisecond)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed after 2 attempts")
+
+	requests.Store(0)
+	err = b.NavigateWithRetry(page, srv.URL, 3, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Equal(t, "ok", page.MustElement("body").MustText())
+}
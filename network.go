@@ -0,0 +1,162 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// AwaitJSON waits for the first response whose URL matches urlPattern and decodes its JSON body into out.
+// The pattern uses the same wildcard syntax as request hijacking, e.g. "*/api/items*".
+// The wait starts when AwaitJSON is called, so the request must be issued afterwards,
+// e.g. by a navigation or click running in another goroutine.
+// A non-2xx response is reported as an error that includes the status.
+func (b *Browser) AwaitJSON(page *rod.Page, urlPattern string, out interface{}, timeout time.Duration) error {
+	reg, err := regexp.Compile(proto.PatternToReg(urlPattern))
+	if err != nil {
+		return fmt.Errorf("invalid url pattern %q: %w", urlPattern, err)
+	}
+
+	p := page.Timeout(timeout)
+	defer p.CancelTimeout()
+
+	var (
+		requestID proto.NetworkRequestID
+		response  *proto.NetworkResponse
+		failure   string
+	)
+
+	wait := p.EachEvent(func(e *proto.NetworkResponseReceived) {
+		if requestID == "" && reg.MatchString(e.Response.URL) {
+			requestID = e.RequestID
+			response = e.Response
+		}
+	}, func(e *proto.NetworkLoadingFinished) bool {
+		return requestID != "" && e.RequestID == requestID
+	}, func(e *proto.NetworkLoadingFailed) bool {
+		if requestID != "" && e.RequestID == requestID {
+			failure = e.ErrorText
+			return true
+		}
+		return false
+	})
+	wait()
+
+	if failure == "" && p.GetContext().Err() != nil {
+		return fmt.Errorf("failed to await response matching %q: %w", urlPattern, p.GetContext().Err())
+	}
+	if failure != "" {
+		return fmt.Errorf("request to %s failed: %s", response.URL, failure)
+	}
+	if response.Status < 200 || response.Status > 299 {
+		return fmt.Errorf("unexpected status %d %s from %s", response.Status, response.StatusText, response.URL)
+	}
+
+	body, err := proto.NetworkGetResponseBody{RequestID: requestID}.Call(p)
+	if err != nil {
+		return fmt.Errorf("failed to get response body: %w", err)
+	}
+
+	data := []byte(body.Body)
+	if body.Base64Encoded {
+		data, err = base64.StdEncoding.DecodeString(body.Body)
+		if err != nil {
+			return fmt.Errorf("failed to decode response body: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response from %s: %w", response.URL, err)
+	}
+
+	return nil
+}
+
+// blockServiceWorkersJS makes service worker registration fail, like in a browser where they're disabled.
+const blockServiceWorkersJS = `(() => {
+	if (typeof ServiceWorkerContainer === 'undefined') return;
+	ServiceWorkerContainer.prototype.register = function () {
+		return Promise.reject(new DOMException('Service workers are disabled', 'SecurityError'));
+	};
+})()`
+
+// WithBlockServiceWorkers keeps service workers out of the page's requests, so responses always come from the network.
+// Requests bypass any service worker already installed for the origin, and new registrations are rejected.
+func WithBlockServiceWorkers() PageOption {
+	return func(page *rod.Page) {
+		page.MustEvalOnNewDocument(blockServiceWorkersJS)
+
+		if err := (proto.NetworkEnable{}).Call(page); err != nil {
+			panic(err)
+		}
+		if err := (proto.NetworkSetBypassServiceWorker{Bypass: true}).Call(page); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RequestFailure is a request of a page that failed to load.
+type RequestFailure struct {
+	URL          string
+	ResourceType proto.NetworkResourceType
+	// ErrorText is the network error reported by the browser, e.g. "net::ERR_NAME_NOT_RESOLVED".
+	ErrorText string
+	// Blocked is true when the request was blocked, by the client or by a security policy such as CSP or CORB.
+	Blocked bool
+	// Canceled is true when the request was canceled, e.g. by a navigation away from the page.
+	Canceled bool
+}
+
+// CaptureFailures starts recording the requests of the page that fail to load, with the reason of each failure.
+// The capture runs until the returned stop function is called, which returns the failures,
+// or until the page is closed.
+func (b *Browser) CaptureFailures(page *rod.Page) (stop func() []RequestFailure) {
+	ctx, cancel := context.WithCancel(page.GetContext())
+
+	var (
+		urls     = make(map[proto.NetworkRequestID]string)
+		failures []RequestFailure
+		mu       sync.Mutex
+		done     = make(chan struct{})
+	)
+
+	wait := page.Context(ctx).EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		urls[e.RequestID] = e.Request.URL
+	}, func(e *proto.NetworkLoadingFinished) {
+		delete(urls, e.RequestID)
+	}, func(e *proto.NetworkLoadingFailed) {
+		failure := RequestFailure{
+			URL:          urls[e.RequestID],
+			ResourceType: e.Type,
+			ErrorText:    e.ErrorText,
+			Blocked:      e.BlockedReason != "" || e.ErrorText == "net::ERR_BLOCKED_BY_CLIENT",
+			Canceled:     e.Canceled,
+		}
+		delete(urls, e.RequestID)
+
+		mu.Lock()
+		failures = append(failures, failure)
+		mu.Unlock()
+	})
+
+	go func() {
+		defer close(done)
+		wait()
+	}()
+
+	return func() []RequestFailure {
+		cancel()
+		<-done
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]RequestFailure(nil), failures...)
+	}
+}
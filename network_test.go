@@ -0,0 +1,125 @@
+package browser
+
+import (
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAwaitJSON(t *testing.T) {
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/item":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":42,"name":"widget"}`))
+		case "/api/missing":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+		default:
+			_, _ = w.Write([]byte(`<html><body><script>
+				setTimeout(() => fetch('/api/item'), 500)
+				setTimeout(() => fetch('/api/missing'), 1000)
+			</script></body></html>`))
+		}
+	}))
+
+	b, page := newTestPage(t)
+
+	var item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	itemErr := make(chan error, 1)
+	go func() {
+		itemErr <- b.AwaitJSON(page, "*/api/item", &item, 10*time.Second)
+	}()
+
+	missingErr := make(chan error, 1)
+	go func() {
+		var out map[string]interface{}
+		missingErr <- b.AwaitJSON(page, "*/api/missing", &out, 10*time.Second)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	page.MustNavigate(srv.URL)
+
+	assert.NoError(t, <-itemErr)
+	assert.Equal(t, 42, item.ID)
+	assert.Equal(t, "widget", item.Name)
+
+	err := <-missingErr
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
+
+func TestWithBlockServiceWorkers(t *testing.T) {
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sw.js":
+			w.Header().Set("Content-Type", "application/javascript")
+			_, _ = w.Write([]byte(`
+				self.addEventListener('install', () => self.skipWaiting());
+				self.addEventListener('activate', e => e.waitUntil(self.clients.claim()));
+				self.addEventListener('fetch', e => {
+					if (e.request.url.endsWith('/data')) e.respondWith(new Response('service-worker'));
+				});
+			`))
+		case "/data":
+			_, _ = w.Write([]byte("network"))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><body><script>
+				window.registration = navigator.serviceWorker.register('/sw.js')
+					.then(() => navigator.serviceWorker.ready)
+					.then(() => 'registered', e => 'rejected');
+			</script></body></html>`))
+		}
+	}))
+
+	b := newTestBrowser(t)
+
+	// Without the option the service worker answers the request.
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	page.MustNavigate(srv.URL).MustWaitLoad()
+	assert.Equal(t, "registered", page.MustEval(`() => window.registration`).String())
+	page.MustReload().MustWaitLoad()
+	assert.Equal(t, "service-worker", page.MustEval(`() => fetch('/data').then(r => r.text())`).String())
+	b.PutPage(page)
+
+	blocked := newTestBrowser(t)
+	page, err = blocked.GetPage(WithBlockServiceWorkers())
+	assert.NoError(t, err)
+	defer blocked.PutPage(page)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+	assert.Equal(t, "rejected", page.MustEval(`() => window.registration`).String())
+	assert.Equal(t, "network", page.MustEval(`() => fetch('/data').then(r => r.text())`).String())
+}
+
+func TestCaptureFailures(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body><img src="/image.png"></body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	assert.NoError(t, b.BlockImageLoading(page))
+	stop := b.CaptureFailures(page)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+	time.Sleep(200 * time.Millisecond)
+
+	failures := stop()
+	if assert.Len(t, failures, 1) {
+		assert.Equal(t, srv.URL+"/image.png", failures[0].URL)
+		assert.Equal(t, proto.NetworkResourceTypeImage, failures[0].ResourceType)
+		assert.Equal(t, "net::ERR_BLOCKED_BY_CLIENT", failures[0].ErrorText)
+		assert.True(t, failures[0].Blocked)
+		assert.False(t, failures[0].Canceled)
+	}
+}
@@ -0,0 +1,65 @@
+package browser
+
+import (
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"io"
+)
+
+// Size of the A4 paper in inches, the default of PDFOptions.
+const (
+	a4Width  = 8.27
+	a4Height = 11.69
+)
+
+// PDFOptions configures PagePDF. The zero value prints A4 portrait pages with backgrounds and no margins.
+// Sizes are in inches.
+type PDFOptions struct {
+	Landscape bool
+	// PaperWidth and PaperHeight are the size of the paper, A4 if zero.
+	PaperWidth  float64
+	PaperHeight float64
+	// Margins around the content of each page.
+	MarginTop    float64
+	MarginBottom float64
+	MarginLeft   float64
+	MarginRight  float64
+	// NoBackground leaves out background colors and images.
+	NoBackground bool
+}
+
+// PagePDF prints the page to PDF, as the browser's print dialog would, and returns the document.
+func (b *Browser) PagePDF(page *rod.Page, opts PDFOptions) ([]byte, error) {
+	width, height := opts.PaperWidth, opts.PaperHeight
+	if width <= 0 {
+		width = a4Width
+	}
+	if height <= 0 {
+		height = a4Height
+	}
+
+	r, err := page.PDF(&proto.PagePrintToPDF{
+		Landscape:       opts.Landscape,
+		PrintBackground: !opts.NoBackground,
+		PaperWidth:      &width,
+		PaperHeight:     &height,
+		MarginTop:       &opts.MarginTop,
+		MarginBottom:    &opts.MarginBottom,
+		MarginLeft:      &opts.MarginLeft,
+		MarginRight:     &opts.MarginRight,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to print page to PDF: %w", err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	return data, nil
+}
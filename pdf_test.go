@@ -0,0 +1,40 @@
+package browser
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// mediaBox matches the page size of a PDF, in points.
+var mediaBox = regexp.MustCompile(`/MediaBox \[0 0 ([\d.]+) ([\d.]+)\]`)
+
+func TestPagePDF(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body style="background: rgb(255, 0, 0)"><h1>Report</h1></body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	for _, landscape := range []bool{false, true} {
+		data, err := b.PagePDF(page, PDFOptions{Landscape: landscape})
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(data, []byte("%PDF-")))
+
+		// A4 is 595x842 points.
+		m := mediaBox.FindSubmatch(data)
+		if assert.NotNil(t, m) {
+			width, _ := strconv.ParseFloat(string(m[1]), 64)
+			height, _ := strconv.ParseFloat(string(m[2]), 64)
+			if landscape {
+				width, height = height, width
+			}
+			assert.InDelta(t, 595, width, 2)
+			assert.InDelta(t, 842, height, 2)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package browser
+
+import (
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"time"
+)
+
+// MemoryStats holds the memory metrics of a page.
+type MemoryStats struct {
+	// JSHeapUsed is the used JavaScript heap size in bytes.
+	JSHeapUsed int64
+	// JSHeapTotal is the total JavaScript heap size in bytes.
+	JSHeapTotal int64
+	// Documents is the number of documents, including those of iframes.
+	Documents int
+	// Nodes is the number of DOM nodes.
+	Nodes int
+	// EventListeners is the number of JavaScript event listeners.
+	EventListeners int
+}
+
+// MemoryUsage returns the memory metrics of the page, useful to detect leaks during long scrapes.
+func (b *Browser) MemoryUsage(page *rod.Page) (MemoryStats, error) {
+	if err := (proto.PerformanceEnable{}).Call(page); err != nil {
+		return MemoryStats{}, fmt.Errorf("failed to enable performance metrics: %w", err)
+	}
+	defer func() {
+		_ = proto.PerformanceDisable{}.Call(page)
+	}()
+
+	res, err := proto.PerformanceGetMetrics{}.Call(page)
+	if err != nil {
+		return MemoryStats{}, fmt.Errorf("failed to get performance metrics: %w", err)
+	}
+
+	var stats MemoryStats
+	for _, metric := range res.Metrics {
+		switch metric.Name {
+		case "JSHeapUsedSize":
+			stats.JSHeapUsed = int64(metric.Value)
+		case "JSHeapTotalSize":
+			stats.JSHeapTotal = int64(metric.Value)
+		case "Documents":
+			stats.Documents = int(metric.Value)
+		case "Nodes":
+			stats.Nodes = int(metric.Value)
+		case "JSEventListeners":
+			stats.EventListeners = int(metric.Value)
+		}
+	}
+
+	return stats, nil
+}
+
+// WebVitals holds the Core Web Vitals style metrics of a page.
+type WebVitals struct {
+	// LCP is the Largest Contentful Paint, the time from navigation start to the render of the largest content.
+	LCP time.Duration
+	// CLS is the Cumulative Layout Shift, the sum of the unexpected layout shift scores.
+	CLS float64
+	// TBT is the Total Blocking Time, the sum of the time beyond 50ms spent by each long task on the main thread.
+	TBT time.Duration
+}
+
+// webVitalsSettle is how long the page must go without new performance entries to be considered settled.
+const webVitalsSettle = 500 * time.Millisecond
+
+// webVitalsJS observes the buffered and upcoming performance entries of the page,
+// and resolves with the metrics once no entry arrived for settle ms, or after max ms.
+const webVitalsJS = `(settle, max) => new Promise(resolve => {
+	const vitals = { lcp: 0, cls: 0, tbt: 0 };
+	const observers = [];
+	let timer;
+	const done = () => {
+		observers.forEach(o => o.disconnect());
+		resolve(vitals);
+	};
+	const wait = () => {
+		clearTimeout(timer);
+		timer = setTimeout(done, settle);
+	};
+	const observe = (type, fn) => {
+		try {
+			const observer = new PerformanceObserver(list => {
+				list.getEntries().forEach(fn);
+				wait();
+			});
+			observer.observe({ type, buffered: true });
+			observers.push(observer);
+		} catch (e) {}
+	};
+	observe('largest-contentful-paint', e => { vitals.lcp = e.renderTime || e.loadTime || e.startTime; });
+	observe('layout-shift', e => { if (!e.hadRecentInput) vitals.cls += e.value; });
+	observe('longtask', e => { vitals.tbt += Math.max(0, e.duration - 50); });
+	wait();
+	setTimeout(done, max);
+})`
+
+// WebVitals waits for the page to load and settle, then returns its LCP, CLS and TBT.
+// The page is settled once no new performance entry was reported for a short while,
+// if it doesn't settle within the timeout the metrics collected so far are returned.
+// Long tasks that happened before the call may be missing from TBT, because browsers don't always buffer them.
+func (b *Browser) WebVitals(page *rod.Page, timeout time.Duration) (WebVitals, error) {
+	deadline := time.Now().Add(timeout)
+
+	// Leave the script some time to report its metrics when it stops at the deadline
+	p := page.Timeout(timeout + time.Second)
+	defer p.CancelTimeout()
+
+	if err := p.WaitLoad(); err != nil {
+		return WebVitals{}, fmt.Errorf("failed to wait for page to load: %w", err)
+	}
+
+	res, err := p.Eval(webVitalsJS, webVitalsSettle.Milliseconds(), time.Until(deadline).Milliseconds())
+	if err != nil {
+		return WebVitals{}, fmt.Errorf("failed to collect web vitals: %w", err)
+	}
+
+	var vitals struct {
+		LCP float64 `json:"lcp"`
+		CLS float64 `json:"cls"`
+		TBT float64 `json:"tbt"`
+	}
+	if err := res.Value.Unmarshal(&vitals); err != nil {
+		return WebVitals{}, fmt.Errorf("failed to decode web vitals: %w", err)
+	}
+
+	return WebVitals{
+		LCP: time.Duration(vitals.LCP * float64(time.Millisecond)),
+		CLS: vitals.CLS,
+		TBT: time.Duration(vitals.TBT * float64(time.Millisecond)),
+	}, nil
+}
+
+// ResourceTiming holds the load timing of a resource of a page.
+type ResourceTiming struct {
+	// Name is the URL of the resource.
+	Name string `json:"name"`
+	// Duration is the time from the start of the fetch to the end of the response.
+	Duration time.Duration `json:"-"`
+	// TransferSize is the size in bytes fetched over the network, zero for cached or cross-origin resources.
+	TransferSize int64 `json:"transferSize"`
+	// InitiatorType is the kind of content that requested the resource, e.g. "img", "script" or "fetch".
+	InitiatorType string `json:"initiatorType"`
+}
+
+// ResourceTimings returns the load timings of the resources fetched by the page, in the order they were requested.
+// Browsers keep a limited number of entries per document, 250 by default.
+func (b *Browser) ResourceTimings(page *rod.Page) ([]ResourceTiming, error) {
+	res, err := page.Eval(`() => performance.getEntriesByType('resource').map(e => ({
+		name: e.name,
+		duration: e.duration,
+		transferSize: e.transferSize,
+		initiatorType: e.initiatorType,
+	}))`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource timings: %w", err)
+	}
+
+	var entries []struct {
+		ResourceTiming
+		Duration float64 `json:"duration"`
+	}
+	if err := res.Value.Unmarshal(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode resource timings: %w", err)
+	}
+
+	timings := make([]ResourceTiming, len(entries))
+	for i, entry := range entries {
+		timings[i] = entry.ResourceTiming
+		timings[i].Duration = time.Duration(entry.Duration * float64(time.Millisecond))
+	}
+
+	return timings, nil
+}
+
+// ttiQuietWindow is how long the main thread must stay free of long tasks for the page to be considered interactive.
+const ttiQuietWindow = 5 * time.Second
+
+// ttiJS resolves with the end of the last long task before the first quiet window after DOMContentLoaded,
+// or with DOMContentLoaded if there's no long task, in milliseconds since navigation start.
+const ttiJS = `(quiet, max) => new Promise(resolve => {
+	const nav = performance.getEntriesByType('navigation')[0];
+	let last = nav ? nav.domContentLoadedEventEnd : 0;
+	let timer;
+	const observer = new PerformanceObserver(list => {
+		for (const e of list.getEntries()) {
+			if (e.startTime + e.duration > last) last = e.startTime + e.duration;
+		}
+		wait();
+	});
+	const done = () => {
+		observer.disconnect();
+		resolve(last);
+	};
+	const wait = () => {
+		clearTimeout(timer);
+		timer = setTimeout(done, Math.max(0, last + quiet - performance.now()));
+	};
+	try {
+		observer.observe({ type: 'longtask', buffered: true });
+	} catch (e) {}
+	wait();
+	setTimeout(done, max);
+})`
+
+// TimeToInteractive waits for the page to load and returns an approximation of its Time to Interactive:
+// the end of the last long task before the main thread stays free of long tasks for 5 seconds,
+// measured from navigation start, or DOMContentLoaded if there is no long task.
+// Unlike the Lighthouse metric it ignores network quietness, and long tasks that happened before the call
+// may be missing when the browser doesn't buffer them. If the page has no quiet window within the timeout,
+// the end of the last long task seen so far is returned.
+func (b *Browser) TimeToInteractive(page *rod.Page, timeout time.Duration) (time.Duration, error) {
+	deadline := time.Now().Add(timeout)
+
+	// Leave the script some time to report when it stops at the deadline
+	p := page.Timeout(timeout + time.Second)
+	defer p.CancelTimeout()
+
+	if err := p.WaitLoad(); err != nil {
+		return 0, fmt.Errorf("failed to wait for page to load: %w", err)
+	}
+
+	res, err := p.Eval(ttiJS, ttiQuietWindow.Milliseconds(), time.Until(deadline).Milliseconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure time to interactive: %w", err)
+	}
+
+	return time.Duration(res.Value.Num() * float64(time.Millisecond)), nil
+}
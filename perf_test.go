@@ -0,0 +1,78 @@
+package browser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMemoryUsage(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body><p>Memory</p><script>window.data = new Array(100000).fill('x')</script></body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL)
+	page.MustWaitLoad()
+
+	stats, err := b.MemoryUsage(page)
+	assert.NoError(t, err)
+	assert.Greater(t, stats.JSHeapUsed, int64(0))
+	assert.GreaterOrEqual(t, stats.JSHeapTotal, stats.JSHeapUsed)
+	assert.Greater(t, stats.Documents, 0)
+	assert.Greater(t, stats.Nodes, 0)
+}
+
+func TestWebVitals(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body><h1>Web Vitals</h1><p style="font-size: 48px">Largest contentful paint</p></body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL)
+
+	vitals, err := b.WebVitals(page, 10*time.Second)
+	assert.NoError(t, err)
+	assert.Greater(t, vitals.LCP, time.Duration(0))
+	assert.GreaterOrEqual(t, vitals.CLS, 0.0)
+	assert.GreaterOrEqual(t, vitals.TBT, time.Duration(0))
+}
+
+func TestResourceTimings(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/":          `<html><body><script src="/script.js"></script></body></html>`,
+		"/script.js": `window.loaded = true`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	timings, err := b.ResourceTimings(page)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, timings) {
+		assert.Equal(t, srv.URL+"/script.js", timings[0].Name)
+		assert.Equal(t, "script", timings[0].InitiatorType)
+		assert.Greater(t, timings[0].Duration, time.Duration(0))
+		assert.Greater(t, timings[0].TransferSize, int64(0))
+	}
+}
+
+func TestTimeToInteractive(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body><p>Busy</p><script>
+			setTimeout(() => { const end = Date.now() + 300; while (Date.now() < end) {} }, 100);
+		</script></body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL)
+
+	tti, err := b.TimeToInteractive(page, 15*time.Second)
+	assert.NoError(t, err)
+	assert.Greater(t, tti, time.Duration(0))
+	assert.Less(t, tti, 10*time.Second)
+}
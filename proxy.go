@@ -0,0 +1,329 @@
+package browser
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WithProxyScheme sets a proxy per URL scheme, e.g. {"http": "127.0.0.1:8080", "https": "127.0.0.1:8443"}.
+// Keys are "http", "https" or "socks", the "socks" proxy is used for the schemes without a proxy of their own.
+// They're combined into a single flag "--proxy-server=http=127.0.0.1:8080;https=127.0.0.1:8443",
+// which takes precedence over WithProxy.
+func WithProxyScheme(proxies map[string]string) Option {
+	return func(b *Browser) {
+		b.proxySchemes = make(map[string]string, len(proxies))
+		for scheme, proxy := range proxies {
+			b.proxySchemes[scheme] = proxy
+		}
+	}
+}
+
+// proxyServer returns the value of the "--proxy-server" flag, or an empty string if no proxy is set.
+func (b *Browser) proxyServer() string {
+	if rules := b.schemeProxyRules(); rules != "" {
+		return rules
+	}
+	return b.proxy
+}
+
+// schemeProxyRules formats the proxies set by WithProxyScheme as Chrome proxy rules, ordered by scheme.
+func (b *Browser) schemeProxyRules() string {
+	schemes := make([]string, 0, len(b.proxySchemes))
+	for scheme := range b.proxySchemes {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	rules := make([]string, len(schemes))
+	for i, scheme := range schemes {
+		rules[i] = scheme + "=" + b.proxySchemes[scheme]
+	}
+
+	return strings.Join(rules, ";")
+}
+
+// WithProxyAuth authenticates with the proxy set by WithProxy or WithProxyScheme using the username and password,
+// answering the proxy's 407 challenges for every page of the pool. NewBrowser returns an error if no proxy is set.
+func WithProxyAuth(username, password string) Option {
+	return func(b *Browser) {
+		b.proxyAuth = &Credential{Username: username, Password: password}
+	}
+}
+
+// pageProxyAuthKey is the key of the credential set by WithPageProxyAuth in the page's context.
+type pageProxyAuthKey struct{}
+
+// WithPageProxyAuth answers the proxy's 407 challenges of the page with the username and password,
+// through the authRequired events of the Fetch domain, so pages of the same browser can authenticate
+// as different users of the proxy. It takes precedence over WithProxyAuth for the page.
+// Like the other page options, it only applies to pages created by GetPage or GetPageInContext,
+// which return an error if the page can't answer the challenges.
+func WithPageProxyAuth(username, password string) PageOption {
+	return func(page *rod.Page) {
+		// The credential travels with the page to GetPage, which sets it up once the options are applied
+		ctx := context.WithValue(page.GetContext(), pageProxyAuthKey{}, &Credential{Username: username, Password: password})
+		*page = *page.Context(ctx)
+	}
+}
+
+// pageProxyAuth returns the credential set by WithPageProxyAuth on the page, or fallback if it has none.
+func pageProxyAuth(page *rod.Page, fallback *Credential) *Credential {
+	if cred, ok := page.GetContext().Value(pageProxyAuthKey{}).(*Credential); ok {
+		return cred
+	}
+	return fallback
+}
+
+// WithProxyHealthCheck loads testURL through the proxy when the browser starts,
+// so NewBrowser returns an error right away if the proxy is unreachable or can't load the url within the timeout.
+// It only applies to proxies set by WithProxy or WithProxyScheme. NewBrowser rejects a timeout that isn't positive.
+func WithProxyHealthCheck(testURL string, timeout time.Duration) Option {
+	return func(b *Browser) {
+		b.proxyCheckURL = testURL
+		b.proxyCheckTimeout = timeout
+	}
+}
+
+// checkProxy loads the health check url of WithProxyHealthCheck in a temporary page of the browser.
+func (b *Browser) checkProxy(browser *rod.Browser) error {
+	if b.proxyCheckURL == "" || b.proxyServer() == "" {
+		return nil
+	}
+
+	page, err := browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return fmt.Errorf("failed to create proxy health check page: %w", err)
+	}
+	defer func() {
+		_ = page.Close()
+	}()
+
+	if b.proxyAuth != nil {
+		if err := b.handleProxyAuth(page, b.proxyAuth); err != nil {
+			return fmt.Errorf("failed to handle proxy authentication: %w", err)
+		}
+	}
+
+	p := page.Timeout(b.proxyCheckTimeout)
+	defer p.CancelTimeout()
+
+	if err := p.Navigate(b.proxyCheckURL); err != nil {
+		return fmt.Errorf("proxy health check through %s failed: %w", b.proxyServer(), err)
+	}
+
+	return nil
+}
+
+// defaultIPEchoURL is the service OutboundIP asks for the IP of the browser, unless WithIPEchoURL is set.
+const defaultIPEchoURL = "https://api.ipify.org"
+
+// WithIPEchoURL sets the service OutboundIP asks for the IP of the browser, which must answer with the bare IP
+// of the client as text, like https://api.ipify.org does by default.
+func WithIPEchoURL(echoURL string) Option {
+	return func(b *Browser) {
+		b.ipEchoURL = echoURL
+	}
+}
+
+// OutboundIP returns the IP the sites visited by the browser see, e.g. to check that its proxy is used.
+// It takes a page from the pool, loads the IP echo service set by WithIPEchoURL and puts the page back.
+func (b *Browser) OutboundIP(ctx context.Context) (string, error) {
+	page, err := b.GetPageWithContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer b.PutPage(page)
+
+	echoURL := b.ipEchoURL
+	if echoURL == "" {
+		echoURL = defaultIPEchoURL
+	}
+
+	p := page.Context(ctx)
+	if err := p.Navigate(echoURL); err != nil {
+		return "", fmt.Errorf("failed to navigate to %s: %w", echoURL, err)
+	}
+	if err := p.WaitLoad(); err != nil {
+		return "", fmt.Errorf("failed to wait for %s to load: %w", echoURL, err)
+	}
+
+	res, err := p.Eval(`() => document.body ? document.body.innerText : ''`)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the answer of %s: %w", echoURL, err)
+	}
+
+	ip := strings.TrimSpace(res.Value.Str())
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("invalid IP %q from %s", ip, echoURL)
+	}
+
+	return ip, nil
+}
+
+// WithProxyRotationPerPage routes each page created by the pool through the next proxy of the rotation,
+// so a single pooled browser spreads its traffic across several proxies.
+// A page keeps its proxy for its whole lifetime, including when it's reused from the pool.
+// Proxies can be given as "host:port" or as URLs such as "http://host:port" or "socks5://host:port".
+//
+// The routing is done by hijacking every request of the page and re-issuing it from Go through the proxy,
+// which is considerably slower than a browser-wide WithProxy and bypasses the browser's HTTP cache.
+func WithProxyRotationPerPage(proxies ...string) Option {
+	return func(b *Browser) {
+		b.proxyRotation = proxies
+	}
+}
+
+// nextProxy returns the next proxy of the rotation, or an empty string if there is no rotation.
+// It must be called with b.mu held.
+func (b *Browser) nextProxy() string {
+	if len(b.proxyRotation) == 0 {
+		return ""
+	}
+
+	proxy := b.proxyRotation[b.proxyIndex%len(b.proxyRotation)]
+	b.proxyIndex++
+
+	return proxy
+}
+
+// routeThroughProxy re-issues every request of the page through the proxy for the page's lifetime.
+func (b *Browser) routeThroughProxy(page *rod.Page, proxy string) error {
+	proxyURL, err := parseProxyURL(proxy)
+	if err != nil {
+		return err
+	}
+
+	client := newHijackClient(b.pageProxy(page))
+
+	// The handler answers every request, so it runs after the handlers added to the page later, such as BlockImageLoading
+	_, err = b.addHijackHandler(page, &hijackHandler{
+		persistent: true,
+		terminal:   true,
+		handle: func(ctx *rod.Hijack) {
+			if err := loadResponse(page, ctx, client); err != nil {
+				ctx.Response.Fail(proto.NetworkErrorReasonConnectionFailed)
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to route page through proxy %s: %w", proxy, err)
+	}
+
+	b.hijackMu.Lock()
+	if h, ok := b.hijacks[page.TargetID]; ok {
+		h.proxy = proxyURL
+	}
+	b.hijackMu.Unlock()
+
+	return nil
+}
+
+// pageProxy returns the proxy of the requests of the page re-issued from Go, the one the browser would use:
+// the page's proxy of WithProxyRotationPerPage, or else the proxy of WithProxyScheme or WithProxy.
+// Like the browser, the proxy of WithProxyScheme or WithProxy is bypassed for loopback addresses.
+// The proxy carries the credential answering the page's proxy challenges, if any.
+func (b *Browser) pageProxy(page *rod.Page) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		var proxy *url.URL
+		auth := b.proxyAuth
+
+		b.hijackMu.Lock()
+		if h, ok := b.hijacks[page.TargetID]; ok {
+			proxy = h.proxy
+			if h.auth != nil {
+				auth = h.auth
+			}
+		}
+		b.hijackMu.Unlock()
+
+		if proxy == nil {
+			// Like the browser, go direct to the loopback addresses
+			if host := req.URL.Hostname(); host == "localhost" || net.ParseIP(host).IsLoopback() {
+				return nil, nil
+			}
+
+			server := b.proxy
+			if len(b.proxySchemes) > 0 {
+				server = b.proxySchemes[req.URL.Scheme]
+				if server == "" {
+					server = b.proxySchemes["socks"]
+				}
+			}
+			if server == "" {
+				return nil, nil
+			}
+
+			var err error
+			if proxy, err = parseProxyURL(server); err != nil {
+				return nil, err
+			}
+		}
+
+		if auth != nil && proxy.User == nil {
+			withAuth := *proxy
+			withAuth.User = url.UserPassword(auth.Username, auth.Password)
+			proxy = &withAuth
+		}
+
+		return proxy, nil
+	}
+}
+
+// newHijackClient returns the client used to re-issue hijacked requests from Go, through the proxy if not nil.
+func newHijackClient(proxy func(*http.Request) (*url.URL, error)) *http.Client {
+	transport := &http.Transport{
+		// Match the browser, which is launched ignoring certificate errors
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		Proxy:           proxy,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		// Let the browser follow redirects itself
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// loadResponse re-issues the hijacked request of the page from Go with the client.
+// The paused request doesn't carry the cookies yet, so the page's cookies for the URL are added to it.
+func loadResponse(page *rod.Page, ctx *rod.Hijack, client *http.Client) error {
+	res, err := proto.NetworkGetCookies{Urls: []string{ctx.Request.URL().String()}}.Call(page)
+	if err != nil {
+		return fmt.Errorf("failed to get cookies: %w", err)
+	}
+
+	if len(res.Cookies) > 0 {
+		cookies := make([]string, len(res.Cookies))
+		for i, cookie := range res.Cookies {
+			cookies[i] = cookie.Name + "=" + cookie.Value
+		}
+		ctx.Request.Req().Header.Set("Cookie", strings.Join(cookies, "; "))
+	}
+
+	return ctx.LoadResponse(client, true)
+}
+
+// parseProxyURL parses a proxy given as "host:port" or as a URL, defaulting to the http scheme.
+func parseProxyURL(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+
+	return u, nil
+}
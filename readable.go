@@ -0,0 +1,105 @@
+package browser
+
+import (
+	"fmt"
+	"github.com/go-rod/rod"
+)
+
+// readableJS extracts the main content of the document, Readability style.
+// Boilerplate such as navigation, headers, footers, sidebars and ads is ignored, the main content is the
+// article or main element if there is one, otherwise the element whose paragraphs score highest by length and commas.
+// The text is made of the headings, paragraphs, list items and quotes of the content, separated by blank lines.
+const readableJS = `() => {
+	const boilerplate = [
+		'script', 'style', 'noscript', 'template', 'iframe', 'form', 'nav', 'header', 'footer', 'aside',
+		'[role=navigation]', '[role=banner]', '[role=contentinfo]', '[role=complementary]', '[aria-hidden=true]',
+	].join(',');
+	const unlikely = /(^|[-_ ])(nav|menu|header|footer|sidebar|comments?|ads?|advert|promo|share|social|related|cookie|banner|breadcrumbs?)([-_ ]|$)/i;
+	const blocks = 'h1,h2,h3,h4,h5,h6,p,li,pre,blockquote';
+
+	const skipped = (el, root) => {
+		for (let node = el; node && node !== root.parentElement; node = node.parentElement) {
+			if (node !== root && node.matches(boilerplate)) return true;
+			if (node !== root && unlikely.test((node.className || '') + ' ' + (node.id || ''))) return true;
+		}
+		return false;
+	};
+
+	let root = document.querySelector('article, [role=main], main');
+	if (!root) {
+		const scores = new Map();
+		for (const p of document.querySelectorAll('p')) {
+			const text = p.innerText.trim();
+			if (text.length < 25 || skipped(p, document.body)) continue;
+			const score = 1 + text.split(',').length + Math.min(text.length / 100, 3);
+			const parent = p.parentElement;
+			if (parent) scores.set(parent, (scores.get(parent) || 0) + score);
+			if (parent && parent.parentElement) scores.set(parent.parentElement, (scores.get(parent.parentElement) || 0) + score / 2);
+		}
+		let best = 0;
+		for (const [el, score] of scores) {
+			if (score > best) {
+				best = score;
+				root = el;
+			}
+		}
+	}
+	root = root || document.body;
+
+	const parts = [];
+	for (const el of root.querySelectorAll(blocks)) {
+		if (el.parentElement.closest(blocks) && root.contains(el.parentElement.closest(blocks))) continue;
+		if (skipped(el, root)) continue;
+		const text = el.innerText.trim();
+		if (text) parts.push(text);
+	}
+
+	const heading = root.querySelector('h1');
+	const title = heading && !skipped(heading, root) ? heading.innerText.trim() : document.title.trim();
+
+	return { title, text: parts.join('\n\n') };
+}`
+
+// ReadableText extracts the title and the text of the main content of the page, like the reader mode of browsers,
+// leaving out navigation, headers, footers, sidebars and ads. The title is the main heading of the content,
+// or the title of the document if the content has none.
+func (b *Browser) ReadableText(page *rod.Page) (title, text string, err error) {
+	res, err := page.Eval(readableJS)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to extract readable text: %w", err)
+	}
+
+	var readable struct {
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	}
+	if err := res.Value.Unmarshal(&readable); err != nil {
+		return "", "", fmt.Errorf("failed to decode readable text: %w", err)
+	}
+
+	return readable.Title, readable.Text, nil
+}
+
+// pageLanguageJS returns the language declared by the document, or the language of the browser.
+const pageLanguageJS = `() => {
+	const lang = document.documentElement.lang.trim();
+	if (lang) return lang;
+
+	const meta = document.querySelector('meta[http-equiv="content-language" i], meta[name="language" i], meta[property="og:locale"]');
+	const content = meta && meta.content.split(',')[0].trim();
+	if (content) return content;
+
+	return navigator.language;
+}`
+
+// PageLanguage returns the primary language of the page, e.g. "es" or "en-US", as declared by the lang attribute
+// of the <html> element, or by a Content-Language, language or og:locale meta tag.
+// If the page declares none, the language of the browser is returned.
+func (b *Browser) PageLanguage(page *rod.Page) (string, error) {
+	res, err := page.Eval(pageLanguageJS)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect page language: %w", err)
+	}
+
+	return res.Value.Str(), nil
+}
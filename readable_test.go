@@ -0,0 +1,68 @@
+package browser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReadableText(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><head><title>The Daily Widget | Home</title></head><body>
+			<header><a href="/">The Daily Widget</a></header>
+			<nav><ul><li>News</li><li>Sports</li></ul></nav>
+			<div class="content">
+				<h1>Widgets Are Back</h1>
+				<p>After years of decline, widgets are once again the talk of the town, with sales up across the board.</p>
+				<p>Analysts say the trend, driven by younger buyers, will continue well into next year.</p>
+				<div class="share-buttons"><p>Share this article on every social network you know.</p></div>
+			</div>
+			<div id="sidebar"><p>Subscribe to our newsletter for more widget news, every single day.</p></div>
+			<footer><p>Copyright The Daily Widget, all rights reserved, forever and ever.</p></footer>
+		</body></html>`,
+		"/article": `<html><head><title>Fallback</title></head><body>
+			<nav>Menu</nav>
+			<article><p>First paragraph.</p><p>Second paragraph.</p></article>
+		</body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	title, text, err := b.ReadableText(page)
+	assert.NoError(t, err)
+	assert.Equal(t, "Widgets Are Back", title)
+	assert.Equal(t, "Widgets Are Back\n\n"+
+		"After years of decline, widgets are once again the talk of the town, with sales up across the board.\n\n"+
+		"Analysts say the trend, driven by younger buyers, will continue well into next year.", text)
+
+	// The article element is the main content, and the document title is used without a heading.
+	page.MustNavigate(srv.URL + "/article").MustWaitLoad()
+
+	title, text, err = b.ReadableText(page)
+	assert.NoError(t, err)
+	assert.Equal(t, "Fallback", title)
+	assert.Equal(t, "First paragraph.\n\nSecond paragraph.", text)
+}
+
+func TestPageLanguage(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/html": `<html lang="es"><body>Hola</body></html>`,
+		"/meta": `<html><head><meta http-equiv="Content-Language" content="fr, en"></head><body>Bonjour</body></html>`,
+		"/none": `<html><body>Hi</body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	for path, want := range map[string]string{
+		"/html": "es",
+		"/meta": "fr",
+		"/none": page.MustEval(`() => navigator.language`).Str(),
+	} {
+		page.MustNavigate(srv.URL + path).MustWaitLoad()
+
+		lang, err := b.PageLanguage(page)
+		assert.NoError(t, err)
+		assert.Equal(t, want, lang, path)
+	}
+}
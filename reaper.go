@@ -0,0 +1,93 @@
+package browser
+
+import (
+	"context"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"time"
+)
+
+// WithReaper checks that the browser is still alive every interval, e.g. to recover from a crashed browser process.
+// A browser that doesn't answer within the interval is evicted: its process is killed, it's removed from the browsers
+// shared by GetBrowser, which launches a fresh one next time, and the next GetPage on the evicted instance relaunches it.
+// The reaper stops when the browser is closed, including by ResetPool.
+func WithReaper(interval time.Duration) Option {
+	return func(b *Browser) {
+		b.reaperInterval = interval
+	}
+}
+
+// reap checks the browser every reaper interval until it's stopped or the browser is found dead and evicted.
+func (b *Browser) reap(browser *rod.Browser, stop chan struct{}) {
+	ticker := time.NewTicker(b.reaperInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if alive(browser, b.reaperInterval) {
+			continue
+		}
+
+		b.evict(browser)
+		return
+	}
+}
+
+// alive reports whether the browser answers a CDP call within the timeout.
+func alive(browser *rod.Browser, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := proto.BrowserGetVersion{}.Call(browser.Context(ctx))
+	return err == nil
+}
+
+// evict forgets the dead browser so it's relaunched when needed, and kills what's left of its process,
+// or drops the connection to a remote browser.
+// It does nothing if the browser was closed or relaunched in the meantime.
+func (b *Browser) evict(browser *rod.Browser) {
+	b.mu.Lock()
+	if b.browser != browser {
+		b.mu.Unlock()
+		return
+	}
+
+	b.stopAllHijacking()
+	b.timer.Stop()
+	if b.launched != nil {
+		b.launched.Kill()
+	} else if b.conn != nil {
+		_ = b.conn.Close()
+		b.conn = nil
+	}
+
+	b.browser = nil
+	b.reaperStop = nil
+	b.initialPages = nil
+	b.contexts = nil
+	b.mu.Unlock()
+
+	b.log().Warn("browser not responding, evicted")
+	b.metrics.BrowserClosed(b.label)
+
+	mu.Lock()
+	if b.sharedKey != "" && browsers[b.sharedKey] == b {
+		delete(browsers, b.sharedKey)
+	}
+	mu.Unlock()
+
+	b.forgetName()
+}
+
+// stopReaper stops the reaper of the browser, if it's running. It must be called with b.mu held.
+func (b *Browser) stopReaper() {
+	if b.reaperStop != nil {
+		close(b.reaperStop)
+		b.reaperStop = nil
+	}
+}
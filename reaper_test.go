@@ -0,0 +1,118 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+// deadClient stands in for the connection to a browser process that died, every call fails.
+type deadClient struct{}
+
+func (deadClient) Event() <-chan *cdp.Event {
+	events := make(chan *cdp.Event)
+	close(events)
+	return events
+}
+
+func (deadClient) Call(context.Context, string, string, interface{}) ([]byte, error) {
+	return nil, errors.New("browser is gone")
+}
+
+func TestWithReaper(t *testing.T) {
+	assert.NotEqual(t, generateKey(), generateKey(WithReaper(time.Second)))
+
+	b, err := GetBrowser(WithReaper(200 * time.Millisecond))
+	assert.NoError(t, err)
+	defer func(b *Browser) {
+		_ = b.Close()
+	}(b)
+
+	// Kill the browser process behind the pool's back.
+	process, err := os.FindProcess(b.launched.PID())
+	assert.NoError(t, err)
+	assert.NoError(t, process.Kill())
+
+	assert.Eventually(t, func() bool {
+		mu.RLock()
+		defer mu.RUnlock()
+		return browsers[b.sharedKey] != b
+	}, 2*time.Second, 50*time.Millisecond)
+
+	// The evicted instance relaunches on demand, with a reaper of its own.
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	page.MustNavigate("about:blank")
+	b.PutPage(page)
+
+	// Closing the browser stops the reaper.
+	assert.NoError(t, b.Close())
+	b.mu.Lock()
+	assert.Nil(t, b.reaperStop)
+	b.mu.Unlock()
+}
+
+func TestPutPageAfterEvict(t *testing.T) {
+	// Connecting fails, but leaves the browser set up to make calls that fail.
+	dead := rod.New().Client(deadClient{})
+	assert.Error(t, dead.Connect())
+
+	b := newDefaultBrowser()
+	b.start(dead, nil)
+	b.timer = time.AfterFunc(time.Hour, func() {})
+	defer b.timer.Stop()
+
+	// A page is checked out when the browser dies.
+	stale := dead.PageFromSession("stale")
+	stale.TargetID = "stale"
+	<-*b.pool
+	b.pages[stale.TargetID] = 0
+	b.checkedOut.Add(1)
+
+	b.evict(dead)
+	b.start(rod.New(), nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.PutPage(stale)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("PutPage blocked on the relaunched pool")
+	}
+
+	assert.Equal(t, int32(0), b.checkedOut.Load())
+	assert.Equal(t, b.poolSize, len(*b.pool))
+	assert.True(t, b.quiet())
+}
+
+func TestRestartAfterFailedClose(t *testing.T) {
+	dead := rod.New().Client(deadClient{})
+	assert.Error(t, dead.Connect())
+
+	b := newDefaultBrowser()
+	b.restartAfter = 1
+	b.start(dead, nil)
+	b.timer = time.AfterFunc(time.Hour, func() {})
+	defer b.timer.Stop()
+	b.served.Store(1)
+
+	// Closing the browser fails, so it's kept.
+	b.mu.Lock()
+	assert.True(t, b.restartDue())
+	assert.NoError(t, b.restart())
+	assert.Equal(t, dead, b.browser)
+
+	// The pool has its slots back, so GetPage doesn't block and the restart is due again.
+	assert.Equal(t, b.poolSize, len(*b.pool))
+	assert.True(t, b.restartDue())
+	b.mu.Unlock()
+}
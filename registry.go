@@ -0,0 +1,124 @@
+package browser
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ReusePolicy decides when GetBrowser shares an existing browser instance with the same options.
+type ReusePolicy struct {
+	maxInstances int
+}
+
+// ShareAlways shares a single browser instance per set of options, however busy it is. It's the default policy.
+var ShareAlways = ReusePolicy{maxInstances: 1}
+
+// ShardWhenBusy shares a browser instance while it has pages left in its pool,
+// and starts another instance with the same options once every page of the existing ones is checked out,
+// up to max instances. When all of them are busy, the least busy one is shared.
+func ShardWhenBusy(max int) ReusePolicy {
+	return ReusePolicy{maxInstances: max}
+}
+
+// instances returns the maximum number of browser instances of the policy.
+func (p ReusePolicy) instances() int {
+	return max(p.maxInstances, 1)
+}
+
+// WithReusePolicy sets the policy GetBrowser uses to share the browser, see ReusePolicy.
+func WithReusePolicy(policy ReusePolicy) Option {
+	return func(b *Browser) {
+		b.reusePolicy = policy
+	}
+}
+
+// shardKey returns the key of a shard of the browsers sharing the options key.
+func shardKey(key string, shard int) string {
+	if shard == 0 {
+		return key
+	}
+	return fmt.Sprintf("%s#%d", key, shard)
+}
+
+// sharedBrowser returns the browser GetBrowser shares for the options key,
+// or nil if a new browser should be created. It must be called with mu held.
+func sharedBrowser(key string, instances int) *Browser {
+	var leastBusy *Browser
+	for shard := 0; shard < instances; shard++ {
+		browser, ok := browsers[shardKey(key, shard)]
+		if !ok {
+			// A shard is free, so don't settle for a busy browser
+			leastBusy = nil
+			break
+		}
+		if !browser.busy() {
+			return browser
+		}
+		if leastBusy == nil || browser.checkedOut.Load() < leastBusy.checkedOut.Load() {
+			leastBusy = browser
+		}
+	}
+
+	return leastBusy
+}
+
+// busy reports whether every page of the browser's pool is checked out.
+func (b *Browser) busy() bool {
+	return int(b.checkedOut.Load()) >= b.poolSize
+}
+
+// namedBrowsers is a map of browser instances registered by name with GetNamedBrowser.
+// Unlike the browsers map, it lets different parts of a program share a browser on purpose,
+// whatever the options they would pass.
+var (
+	namedBrowsers = make(map[string]*Browser)
+	namedMu       sync.Mutex
+)
+
+// GetNamedBrowser returns the browser instance registered with the name.
+// If there is none, it creates a new browser instance with the provided options and registers it,
+// otherwise the options are ignored. The browser stays registered until it's closed.
+func GetNamedBrowser(name string, options ...Option) (*Browser, error) {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+
+	if browser, ok := namedBrowsers[name]; ok {
+		return browser, nil
+	}
+
+	browser, err := NewBrowser(options...)
+	if err != nil {
+		return nil, err
+	}
+	browser.name = name
+	namedBrowsers[name] = browser
+
+	return browser, nil
+}
+
+// CloseNamed closes the browser instance registered with the name and removes it from the registry.
+// It does nothing if no browser is registered with the name.
+func CloseNamed(name string) error {
+	namedMu.Lock()
+	browser, ok := namedBrowsers[name]
+	namedMu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return browser.Close()
+}
+
+// forgetName removes the browser from the registry of named browsers if it's registered.
+func (b *Browser) forgetName() {
+	if b.name == "" {
+		return
+	}
+
+	namedMu.Lock()
+	if namedBrowsers[b.name] == b {
+		delete(namedBrowsers, b.name)
+	}
+	namedMu.Unlock()
+}
@@ -0,0 +1,90 @@
+package browser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNamedBrowser(t *testing.T) {
+	b1, err := GetNamedBrowser("shared")
+	assert.NoError(t, err)
+
+	b2, err := GetNamedBrowser("shared", WithPoolSize(5))
+	assert.NoError(t, err)
+	assert.Same(t, b1, b2)
+	assert.Equal(t, 3, b2.poolSize, "Options are ignored for an existing named browser")
+
+	// A named browser is not shared through GetBrowser and closing it leaves the shared ones alone.
+	shared, err := GetBrowser()
+	assert.NoError(t, err)
+	assert.NotSame(t, b1, shared)
+
+	assert.NoError(t, CloseNamed("shared"))
+	assert.Nil(t, b1.browser)
+	assert.NotNil(t, shared.browser)
+
+	mu.RLock()
+	assert.Same(t, shared, browsers[generateKey()])
+	mu.RUnlock()
+	assert.NoError(t, shared.Close())
+
+	b3, err := GetNamedBrowser("shared")
+	assert.NoError(t, err)
+	assert.NotSame(t, b1, b3)
+	assert.NoError(t, CloseNamed("shared"))
+
+	assert.NoError(t, CloseNamed("missing"))
+}
+
+func TestGenerateKeyWithReusePolicy(t *testing.T) {
+	assert.Equal(t, generateKey(), generateKey(WithReusePolicy(ShareAlways)))
+	assert.NotEqual(t, generateKey(), generateKey(WithReusePolicy(ShardWhenBusy(2))))
+}
+
+func TestWithReusePolicy(t *testing.T) {
+	defer func() {
+		_ = ResetPool()
+	}()
+
+	options := []Option{WithPoolSize(1), WithReusePolicy(ShardWhenBusy(2))}
+
+	b1, err := GetBrowser(options...)
+	assert.NoError(t, err)
+
+	again, err := GetBrowser(options...)
+	assert.NoError(t, err)
+	assert.Same(t, b1, again, "An idle browser should be shared")
+
+	// Saturate the pool of the first browser.
+	page, err := b1.GetPage()
+	assert.NoError(t, err)
+
+	b2, err := GetBrowser(options...)
+	assert.NoError(t, err)
+	assert.NotSame(t, b1, b2, "A busy browser should get a shard")
+
+	// Once every shard is busy, the least busy one is shared.
+	page2, err := b2.GetPage()
+	assert.NoError(t, err)
+	b3, err := GetBrowser(options...)
+	assert.NoError(t, err)
+	assert.True(t, b3 == b1 || b3 == b2)
+
+	b1.PutPage(page)
+	b2.PutPage(page2)
+
+	b4, err := GetBrowser(options...)
+	assert.NoError(t, err)
+	assert.Same(t, b1, b4)
+
+	// The default policy shares the browser even when it's busy.
+	shared, err := GetBrowser(WithPoolSize(1))
+	assert.NoError(t, err)
+	page, err = shared.GetPage()
+	assert.NoError(t, err)
+	defer shared.PutPage(page)
+
+	again, err = GetBrowser(WithPoolSize(1))
+	assert.NoError(t, err)
+	assert.Same(t, shared, again)
+}
@@ -0,0 +1,252 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// screenshotOptions configures ScreenshotFullPage.
+type screenshotOptions struct {
+	// scrollStep is the auto scroll step in pixels, zero disables auto scroll and a negative step scrolls by viewport.
+	scrollStep  int
+	scrollDelay time.Duration
+}
+
+// ScreenshotOption is a function type for configuring ScreenshotFullPage.
+type ScreenshotOption func(*screenshotOptions)
+
+// WithAutoScroll scrolls through the whole page before the capture, step pixels at a time waiting delay between steps,
+// so lazy-loaded content below the fold is loaded. The images that started loading are awaited before the capture.
+// A step of zero or less scrolls one viewport height at a time.
+func WithAutoScroll(step int, delay time.Duration) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		if step <= 0 {
+			step = -1
+		}
+		o.scrollStep = step
+		o.scrollDelay = delay
+	}
+}
+
+// autoScrollJS scrolls to the bottom of the page step by step, back to the top, then waits for pending images.
+const autoScrollJS = `async (step, delay) => {
+	const sleep = ms => new Promise(resolve => setTimeout(resolve, ms));
+	if (step <= 0) step = window.innerHeight;
+	for (let y = 0; y < document.documentElement.scrollHeight; y += step) {
+		window.scrollTo(0, y);
+		await sleep(delay);
+	}
+	window.scrollTo(0, 0);
+	await Promise.all(Array.from(document.images)
+		.filter(img => !img.complete)
+		.map(img => new Promise(resolve => {
+			img.addEventListener('load', resolve, { once: true });
+			img.addEventListener('error', resolve, { once: true });
+		})));
+}`
+
+// Screenshot captures the part of the page visible in the viewport as PNG.
+func (b *Browser) Screenshot(page *rod.Page) ([]byte, error) {
+	res, err := proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormatPng,
+	}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+
+	return res.Data, nil
+}
+
+// StartPeriodicScreenshots captures the viewport of the page as PNG every interval into dir, created if needed,
+// naming each file after the UTC time of the capture, e.g. "20240102T150405.000000000Z.png", so they sort in order.
+// The capture runs until the returned stop function is called, or until the page is closed.
+// A capture that fails or doesn't complete within the interval, e.g. while the page is navigating, is skipped
+// and logged, and the next tick tries again.
+func (b *Browser) StartPeriodicScreenshots(page *rod.Page, dir string, interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(page.GetContext())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			b.log().Warn("failed to create screenshot directory", "dir", dir, "error", err)
+			return
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := b.periodicScreenshot(page.Context(ctx), dir, now, interval); err != nil && ctx.Err() == nil {
+					b.log().Warn("periodic screenshot skipped", "error", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// periodicScreenshot captures the viewport into a file of dir named after the time, giving up after the timeout.
+func (b *Browser) periodicScreenshot(page *rod.Page, dir string, at time.Time, timeout time.Duration) error {
+	p := page.Timeout(timeout)
+	defer p.CancelTimeout()
+
+	data, err := b.Screenshot(p)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Join(dir, at.UTC().Format("20060102T150405.000000000Z")+".png")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write screenshot: %w", err)
+	}
+
+	return nil
+}
+
+// ScreenshotElements captures every element matching the selector as PNG, keyed by the index of the element
+// among the matches. Elements that aren't rendered, or whose box is empty, are skipped and have no entry,
+// e.g. elements hidden with display: none or visibility: hidden.
+func (b *Browser) ScreenshotElements(page *rod.Page, selector string) (map[int][]byte, error) {
+	elements, err := page.Elements(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find elements %s: %w", selector, err)
+	}
+
+	shots := make(map[int][]byte, len(elements))
+	for i, el := range elements {
+		visible, err := el.Visible()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check visibility of element %d of %s: %w", i, selector, err)
+		}
+		if !visible {
+			continue
+		}
+
+		shape, err := el.Shape()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the position of element %d of %s: %w", i, selector, err)
+		}
+		if box := shape.Box(); box == nil || box.Width == 0 || box.Height == 0 {
+			continue
+		}
+
+		data, err := el.Screenshot(proto.PageCaptureScreenshotFormatPng, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture element %d of %s: %w", i, selector, err)
+		}
+		shots[i] = data
+	}
+
+	return shots, nil
+}
+
+// ScreenshotFullPage captures the entire scrollable page as PNG, not only the part visible in the viewport.
+func (b *Browser) ScreenshotFullPage(page *rod.Page, opts ...ScreenshotOption) ([]byte, error) {
+	var o screenshotOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.scrollStep != 0 {
+		if _, err := page.Eval(autoScrollJS, o.scrollStep, o.scrollDelay.Milliseconds()); err != nil {
+			return nil, fmt.Errorf("failed to scroll through page: %w", err)
+		}
+	}
+
+	metrics, err := proto.PageGetLayoutMetrics{}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page size: %w", err)
+	}
+
+	res, err := proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormatPng,
+		Clip: &proto.PageViewport{
+			Width:  metrics.CSSContentSize.Width,
+			Height: metrics.CSSContentSize.Height,
+			Scale:  1,
+		},
+		CaptureBeyondViewport: true,
+	}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+
+	return res.Data, nil
+}
+
+// ScreenshotHash returns the average hash of the part of the page visible in the viewport, a perceptual hash
+// where visually similar renders get hashes that differ by few bits, e.g. to find duplicate pages.
+// The number of differing bits of two hashes, bits.OnesCount64(h1 ^ h2), measures how different the renders are.
+func (b *Browser) ScreenshotHash(page *rod.Page) (uint64, error) {
+	data, err := b.Screenshot(page)
+	if err != nil {
+		return 0, err
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+
+	return averageHash(img), nil
+}
+
+// averageHash shrinks the image to 8x8 gray cells and sets the bit of each cell brighter than the average.
+func averageHash(img image.Image) uint64 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	var cells [64]float64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			x0, x1 := bounds.Min.X+x*w/8, bounds.Min.X+max((x+1)*w/8, x*w/8+1)
+			y0, y1 := bounds.Min.Y+y*h/8, bounds.Min.Y+max((y+1)*h/8, y*h/8+1)
+
+			var sum float64
+			var n int
+			for py := y0; py < y1 && py < bounds.Max.Y; py++ {
+				for px := x0; px < x1 && px < bounds.Max.X; px++ {
+					r, g, bl, _ := img.At(px, py).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)
+					n++
+				}
+			}
+			if n > 0 {
+				cells[y*8+x] = sum / float64(n)
+			}
+		}
+	}
+
+	var mean float64
+	for _, c := range cells {
+		mean += c
+	}
+	mean /= 64
+
+	var hash uint64
+	for i, c := range cells {
+		if c > mean {
+			hash |= 1 << i
+		}
+	}
+
+	return hash
+}
@@ -0,0 +1,206 @@
+package browser
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/png"
+	"math/bits"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newImageServer serves a page with a lazy-loaded red image far below the fold.
+func newImageServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for x := 0; x < 100; x++ {
+		for y := 0; y < 100; y++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	var red bytes.Buffer
+	assert.NoError(t, png.Encode(&red, img))
+
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/red.png" {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(red.Bytes())
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body style="margin: 0">
+			<div style="height: 3000px"></div>
+			<img loading="lazy" src="/red.png" width="100" height="100" style="display: block">
+		</body></html>`))
+	}))
+
+	return srv
+}
+
+func TestScreenshotFullPageAutoScroll(t *testing.T) {
+	srv := newImageServer(t)
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage(WithViewport(800, 600, 1, false))
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	data, err := b.ScreenshotFullPage(page, WithAutoScroll(300, 50*time.Millisecond))
+	assert.NoError(t, err)
+
+	shot, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, shot.Bounds().Dy(), 3100)
+
+	r, g, bl, _ := shot.At(50, 3050).RGBA()
+	assert.True(t, r > 0xf000 && g < 0x1000 && bl < 0x1000, "The lazy image should be captured")
+}
+
+func TestScreenshot(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body style="margin: 0; height: 3000px; background: rgb(255, 0, 0)"></body></html>`,
+	})
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage(WithViewport(800, 600, 1, false))
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	data, err := b.Screenshot(page)
+	assert.NoError(t, err)
+
+	shot, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 800, shot.Bounds().Dx())
+	assert.Equal(t, 600, shot.Bounds().Dy())
+
+	// The full page screenshot goes beyond the viewport.
+	data, err = b.ScreenshotFullPage(page)
+	assert.NoError(t, err)
+
+	shot, err = png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 3000, shot.Bounds().Dy())
+}
+
+func TestStartPeriodicScreenshots(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/":     `<html><body style="background: rgb(255, 0, 0)"></body></html>`,
+		"/next": `<html><body style="background: rgb(0, 0, 255)"></body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	dir := filepath.Join(t.TempDir(), "shots")
+	stop := b.StartPeriodicScreenshots(page, dir, 200*time.Millisecond)
+
+	// Navigating while capturing doesn't stop the captures.
+	time.Sleep(500 * time.Millisecond)
+	page.MustNavigate(srv.URL + "/next").MustWaitLoad()
+	time.Sleep(700 * time.Millisecond)
+	stop()
+
+	shots, err := filepath.Glob(filepath.Join(dir, "*.png"))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(shots), 3)
+	for _, shot := range shots {
+		data, err := os.ReadFile(shot)
+		assert.NoError(t, err)
+		_, err = png.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+	}
+
+	// Nothing is captured once stopped.
+	time.Sleep(500 * time.Millisecond)
+	after, err := filepath.Glob(filepath.Join(dir, "*.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, len(shots), len(after))
+}
+
+func TestScreenshotElements(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body style="margin: 0">
+			<ul style="margin: 0; padding: 0; list-style: none">
+				<li style="width: 100px; height: 20px; background: red">One</li>
+				<li style="display: none">Hidden</li>
+				<li style="width: 150px; height: 30px; background: green">Two</li>
+				<li style="width: 0; height: 0; overflow: hidden"></li>
+				<li style="width: 200px; height: 40px; background: blue">Three</li>
+			</ul>
+		</body></html>`,
+	})
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage(WithViewport(800, 600, 1, false))
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	shots, err := b.ScreenshotElements(page, "li")
+	assert.NoError(t, err)
+	assert.Len(t, shots, 3)
+
+	for i, width := range map[int]int{0: 100, 2: 150, 4: 200} {
+		if assert.Contains(t, shots, i) {
+			shot, err := png.Decode(bytes.NewReader(shots[i]))
+			assert.NoError(t, err)
+			assert.Equal(t, width, shot.Bounds().Dx())
+		}
+	}
+}
+
+func TestAverageHash(t *testing.T) {
+	// The left half is white and the right half black.
+	img := image.NewGray(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	assert.Equal(t, uint64(0x0f0f0f0f0f0f0f0f), averageHash(img))
+
+	// A uniform image has no cell brighter than the average.
+	assert.Equal(t, uint64(0), averageHash(image.NewGray(image.Rect(0, 0, 5, 5))))
+}
+
+func TestScreenshotHash(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/a": `<html><body style="margin: 0"><div style="height: 50vh; background: black"></div></body></html>`,
+		"/b": `<html><body style="margin: 0"><div style="width: 50vw; height: 100vh; background: black"></div></body></html>`,
+	})
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage(WithViewport(800, 600, 1, false))
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	page.MustNavigate(srv.URL + "/a").MustWaitLoad()
+	h1, err := b.ScreenshotHash(page)
+	assert.NoError(t, err)
+	h2, err := b.ScreenshotHash(page)
+	assert.NoError(t, err)
+	assert.Equal(t, h1, h2)
+
+	page.MustNavigate(srv.URL + "/b").MustWaitLoad()
+	h3, err := b.ScreenshotHash(page)
+	assert.NoError(t, err)
+	assert.Greater(t, bits.OnesCount64(h1^h3), 10)
+}
@@ -0,0 +1,27 @@
+package browser
+
+import (
+	"fmt"
+	"github.com/go-rod/rod"
+)
+
+// ScrollTo scrolls the page to the position, in CSS pixels from the top left corner of the document.
+// The browser clamps the position to the scrollable area.
+func (b *Browser) ScrollTo(page *rod.Page, x, y float64) error {
+	if _, err := page.Eval(`(x, y) => window.scrollTo({ left: x, top: y, behavior: 'instant' })`, x, y); err != nil {
+		return fmt.Errorf("failed to scroll to %g,%g: %w", x, y, err)
+	}
+
+	return nil
+}
+
+// ScrollPosition returns the scroll position of the page, in CSS pixels from the top left corner of the document.
+func (b *Browser) ScrollPosition(page *rod.Page) (x, y float64, err error) {
+	res, err := page.Eval(`() => [window.scrollX, window.scrollY]`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get scroll position: %w", err)
+	}
+
+	position := res.Value.Arr()
+	return position[0].Num(), position[1].Num(), nil
+}
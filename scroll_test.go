@@ -0,0 +1,37 @@
+package browser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScrollTo(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body style="margin: 0"><div style="width: 5000px; height: 5000px"></div></body></html>`,
+	})
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage(WithViewport(800, 600, 1, false))
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	x, y, err := b.ScrollPosition(page)
+	assert.NoError(t, err)
+	assert.Zero(t, x)
+	assert.Zero(t, y)
+
+	assert.NoError(t, b.ScrollTo(page, 120, 1500))
+	x, y, err = b.ScrollPosition(page)
+	assert.NoError(t, err)
+	assert.Equal(t, 120.0, x)
+	assert.Equal(t, 1500.0, y)
+
+	// Positions beyond the document are clamped.
+	assert.NoError(t, b.ScrollTo(page, 0, 100000))
+	_, y, err = b.ScrollPosition(page)
+	assert.NoError(t, err)
+	assert.InDelta(t, 4400.0, y, 20)
+}
@@ -0,0 +1,107 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// snapshotStyles are the computed styles captured for each rendered node of a DOMSnapshot.
+var snapshotStyles = []string{
+	"display",
+	"visibility",
+	"position",
+	"color",
+	"background-color",
+	"font-family",
+	"font-size",
+	"font-weight",
+}
+
+// DOMSnapshot is a normalized snapshot of the DOM of a page.
+// It only contains resolved names, values and styles, without the ids assigned by the browser,
+// so two snapshots of identical content are equal and can be compared with reflect.DeepEqual.
+type DOMSnapshot struct {
+	URL   string
+	Title string
+	Nodes []DOMSnapshotNode
+}
+
+// DOMSnapshotNode is a node of a DOMSnapshot.
+type DOMSnapshotNode struct {
+	// Parent is the index of the parent node in DOMSnapshot.Nodes, or -1 for the document node.
+	Parent int
+	// Type is the DOM node type, e.g. 1 for elements and 3 for text.
+	Type       int
+	Name       string
+	Value      string
+	Attributes map[string]string
+	// Styles holds the computed styles of rendered nodes, it's nil for nodes without layout.
+	Styles map[string]string
+}
+
+// DOMSnapshot captures the DOM of the page's main document, including the computed styles of rendered nodes.
+func (b *Browser) DOMSnapshot(page *rod.Page) (DOMSnapshot, error) {
+	res, err := proto.DOMSnapshotCaptureSnapshot{ComputedStyles: snapshotStyles}.Call(page)
+	if err != nil {
+		return DOMSnapshot{}, fmt.Errorf("failed to capture dom snapshot: %w", err)
+	}
+	if len(res.Documents) == 0 || res.Documents[0].Nodes == nil {
+		return DOMSnapshot{}, errors.New("failed to capture dom snapshot: no document")
+	}
+
+	str := func(i proto.DOMSnapshotStringIndex) string {
+		if i < 0 || int(i) >= len(res.Strings) {
+			return ""
+		}
+		return res.Strings[i]
+	}
+
+	doc := res.Documents[0]
+	nodes := doc.Nodes
+	snapshot := DOMSnapshot{
+		URL:   str(doc.DocumentURL),
+		Title: str(doc.Title),
+		Nodes: make([]DOMSnapshotNode, len(nodes.NodeName)),
+	}
+
+	for i := range snapshot.Nodes {
+		node := DOMSnapshotNode{Parent: -1}
+		if i < len(nodes.ParentIndex) {
+			node.Parent = nodes.ParentIndex[i]
+		}
+		if i < len(nodes.NodeType) {
+			node.Type = nodes.NodeType[i]
+		}
+		node.Name = str(nodes.NodeName[i])
+		if i < len(nodes.NodeValue) {
+			node.Value = str(nodes.NodeValue[i])
+		}
+		if i < len(nodes.Attributes) && len(nodes.Attributes[i]) > 0 {
+			attrs := nodes.Attributes[i]
+			node.Attributes = make(map[string]string, len(attrs)/2)
+			for j := 0; j+1 < len(attrs); j += 2 {
+				node.Attributes[str(attrs[j])] = str(attrs[j+1])
+			}
+		}
+		snapshot.Nodes[i] = node
+	}
+
+	if layout := doc.Layout; layout != nil {
+		for i, nodeIndex := range layout.NodeIndex {
+			if i >= len(layout.Styles) || nodeIndex < 0 || nodeIndex >= len(snapshot.Nodes) {
+				continue
+			}
+			styles := make(map[string]string, len(snapshotStyles))
+			for j, value := range layout.Styles[i] {
+				if j < len(snapshotStyles) {
+					styles[snapshotStyles[j]] = str(value)
+				}
+			}
+			snapshot.Nodes[nodeIndex].Styles = styles
+		}
+	}
+
+	return snapshot, nil
+}
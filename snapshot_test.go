@@ -0,0 +1,45 @@
+package browser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDOMSnapshot(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/":        `<html><head><title>Snapshot</title></head><body><h1 class="title" style="color: red">Hello</h1><p>World</p></body></html>`,
+		"/changed": `<html><head><title>Snapshot</title></head><body><h1 class="title" style="color: blue">Hello</h1><p>World</p></body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL)
+	page.MustWaitLoad()
+	first, err := b.DOMSnapshot(page)
+	assert.NoError(t, err)
+	assert.Equal(t, "Snapshot", first.Title)
+	assert.NotEmpty(t, first.Nodes)
+
+	page.MustReload()
+	page.MustWaitLoad()
+	second, err := b.DOMSnapshot(page)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second, "Identical content should produce equal snapshots")
+
+	var heading *DOMSnapshotNode
+	for i := range first.Nodes {
+		if first.Nodes[i].Name == "H1" {
+			heading = &first.Nodes[i]
+		}
+	}
+	if assert.NotNil(t, heading) {
+		assert.Equal(t, "title", heading.Attributes["class"])
+		assert.Equal(t, "rgb(255, 0, 0)", heading.Styles["color"])
+	}
+
+	page.MustNavigate(srv.URL + "/changed")
+	page.MustWaitLoad()
+	changed, err := b.DOMSnapshot(page)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Nodes, changed.Nodes)
+}
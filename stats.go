@@ -0,0 +1,87 @@
+package browser
+
+import (
+	"fmt"
+	"github.com/go-rod/rod/lib/proto"
+	"time"
+)
+
+// PoolStats is a snapshot of the page pool of a browser, see Browser.Stats.
+type PoolStats struct {
+	// Label is the label of the browser set by WithLabel, empty if it has none.
+	Label string
+	// Size is the maximum number of pages of the pool.
+	Size int
+	// Available is the number of pages that can be taken without waiting, including pages not created yet.
+	Available int
+	// InUse is the number of pages currently taken from the pool.
+	InUse int
+	// Running reports whether the browser process is launched.
+	Running bool
+	// LastUsed is the last time a page was taken from or put back into the pool.
+	LastUsed time.Time
+	// IdleCloseIn is the time left before the idle timeout closes the browser, zero if it isn't running.
+	IdleCloseIn time.Duration
+}
+
+// Stats returns the current usage of the page pool, e.g. to monitor pool pressure.
+func (b *Browser) Stats() PoolStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := PoolStats{
+		Label:     b.label,
+		Size:      b.poolSize,
+		Available: b.poolSize,
+		InUse:     int(b.checkedOut.Load()),
+		Running:   b.browser != nil,
+		LastUsed:  b.lastUsed,
+	}
+
+	if b.browser != nil {
+		stats.Available = len(*b.pool)
+		stats.IdleCloseIn = max(b.idleTimeout-time.Since(b.lastUsed), 0)
+	}
+
+	return stats
+}
+
+// TargetInfo describes a target open in the browser, such as a page, an iframe or a worker.
+type TargetInfo struct {
+	ID    proto.TargetTargetID
+	Type  proto.TargetTargetInfoType
+	URL   string
+	Title string
+	// Attached reports whether a DevTools client is attached to the target, e.g. for the pages of the pool.
+	Attached bool
+}
+
+// ListTargets returns the targets open in the browser, pages of the pool or not, and workers alike.
+// It returns no targets if the browser isn't running.
+func (b *Browser) ListTargets() ([]TargetInfo, error) {
+	b.mu.Lock()
+	browser := b.browser
+	b.mu.Unlock()
+
+	if browser == nil {
+		return nil, nil
+	}
+
+	res, err := proto.TargetGetTargets{}.Call(browser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list targets: %w", err)
+	}
+
+	targets := make([]TargetInfo, len(res.TargetInfos))
+	for i, info := range res.TargetInfos {
+		targets[i] = TargetInfo{
+			ID:       info.TargetID,
+			Type:     info.Type,
+			URL:      info.URL,
+			Title:    info.Title,
+			Attached: info.Attached,
+		}
+	}
+
+	return targets, nil
+}
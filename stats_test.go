@@ -0,0 +1,82 @@
+package browser
+
+import (
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStatsLabel(t *testing.T) {
+	b := newTestBrowser(t, WithLabel("crawler"), WithLazyLaunch(true))
+
+	assert.Equal(t, "crawler", b.Stats().Label)
+}
+
+func TestStats(t *testing.T) {
+	b := newTestBrowser(t, WithPoolSize(3), WithIdleTimeout(time.Minute), WithLazyLaunch(true))
+
+	stats := b.Stats()
+	assert.False(t, stats.Running)
+	assert.Equal(t, 3, stats.Size)
+	assert.Equal(t, 3, stats.Available)
+	assert.Equal(t, 0, stats.InUse)
+	assert.Zero(t, stats.IdleCloseIn)
+
+	page1, err := b.GetPage()
+	assert.NoError(t, err)
+	page2, err := b.GetPage()
+	assert.NoError(t, err)
+
+	stats = b.Stats()
+	assert.True(t, stats.Running)
+	assert.Equal(t, 3, stats.Size)
+	assert.Equal(t, 1, stats.Available)
+	assert.Equal(t, 2, stats.InUse)
+	assert.WithinDuration(t, time.Now(), stats.LastUsed, 5*time.Second)
+	assert.Greater(t, stats.IdleCloseIn, 50*time.Second)
+	assert.LessOrEqual(t, stats.IdleCloseIn, time.Minute)
+
+	b.PutPage(page1)
+	b.PutPage(page2)
+
+	stats = b.Stats()
+	assert.Equal(t, 3, stats.Available)
+	assert.Equal(t, 0, stats.InUse)
+}
+
+func TestListTargets(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/one": `<html><head><title>One</title></head><body></body></html>`,
+		"/two": `<html><head><title>Two</title></head><body></body></html>`,
+	})
+
+	b := newTestBrowser(t, WithLazyLaunch(true))
+
+	targets, err := b.ListTargets()
+	assert.NoError(t, err)
+	assert.Empty(t, targets)
+
+	page1, err := b.GetPage()
+	assert.NoError(t, err)
+	defer b.PutPage(page1)
+	page2, err := b.GetPage()
+	assert.NoError(t, err)
+	defer b.PutPage(page2)
+
+	page1.MustNavigate(srv.URL + "/one").MustWaitLoad()
+	page2.MustNavigate(srv.URL + "/two").MustWaitLoad()
+
+	targets, err = b.ListTargets()
+	assert.NoError(t, err)
+
+	found := make(map[proto.TargetTargetID]TargetInfo)
+	for _, target := range targets {
+		found[target.ID] = target
+	}
+	assert.Equal(t, srv.URL+"/one", found[page1.TargetID].URL)
+	assert.Equal(t, "One", found[page1.TargetID].Title)
+	assert.Equal(t, proto.TargetTargetInfoTypePage, found[page1.TargetID].Type)
+	assert.Equal(t, srv.URL+"/two", found[page2.TargetID].URL)
+	assert.Equal(t, "Two", found[page2.TargetID].Title)
+}
@@ -0,0 +1,142 @@
+package browser
+
+import (
+	"fmt"
+	"github.com/go-rod/rod"
+	"math/rand"
+)
+
+// WithHardwareProfile overrides navigator.hardwareConcurrency and navigator.deviceMemory for the page,
+// so fingerprinting scripts see the given number of CPU cores and amount of memory in gigabytes.
+func WithHardwareProfile(cores int, memoryGB float64) PageOption {
+	return func(page *rod.Page) {
+		page.MustEvalOnNewDocument(fmt.Sprintf(`(() => {
+			Object.defineProperty(Navigator.prototype, 'hardwareConcurrency', { get: () => %d, configurable: true });
+			Object.defineProperty(Navigator.prototype, 'deviceMemory', { get: () => %g, configurable: true });
+		})()`, cores, memoryGB))
+	}
+}
+
+// fakePluginsJS replaces navigator.plugins and navigator.mimeTypes with the PDF viewer entries of a desktop Chrome.
+const fakePluginsJS = `(() => {
+	const names = ['PDF Viewer', 'Chrome PDF Viewer', 'Chromium PDF Viewer', 'Microsoft Edge PDF Viewer', 'WebKit built-in PDF'];
+	const types = ['application/pdf', 'text/pdf'];
+
+	const makeArray = (proto, items, key) => {
+		const arr = Object.create(proto);
+		items.forEach((item, i) => {
+			Object.defineProperty(arr, i, { value: item, enumerable: true });
+			Object.defineProperty(arr, item[key], { value: item });
+		});
+		Object.defineProperties(arr, {
+			length: { value: items.length },
+			item: { value: i => items[i] || null },
+			namedItem: { value: name => items.find(item => item[key] === name) || null },
+			refresh: { value: () => {} },
+			[Symbol.iterator]: { value: () => items[Symbol.iterator]() },
+		});
+		return arr;
+	};
+
+	const mimeTypes = types.map(type => Object.create(MimeType.prototype, {
+		type: { value: type, enumerable: true },
+		suffixes: { value: 'pdf', enumerable: true },
+		description: { value: 'Portable Document Format', enumerable: true },
+	}));
+
+	const plugins = names.map(name => {
+		const plugin = makeArray(Plugin.prototype, mimeTypes, 'type');
+		Object.defineProperties(plugin, {
+			name: { value: name, enumerable: true },
+			filename: { value: 'internal-pdf-viewer', enumerable: true },
+			description: { value: 'Portable Document Format', enumerable: true },
+		});
+		return plugin;
+	});
+
+	mimeTypes.forEach(mimeType => Object.defineProperty(mimeType, 'enabledPlugin', { value: plugins[0], enumerable: true }));
+
+	const pluginArray = makeArray(PluginArray.prototype, plugins, 'name');
+	const mimeTypeArray = makeArray(MimeTypeArray.prototype, mimeTypes, 'type');
+
+	Object.defineProperty(Navigator.prototype, 'plugins', { get: () => pluginArray, configurable: true });
+	Object.defineProperty(Navigator.prototype, 'mimeTypes', { get: () => mimeTypeArray, configurable: true });
+	Object.defineProperty(Navigator.prototype, 'pdfViewerEnabled', { get: () => true, configurable: true });
+})()`
+
+// WithFakePlugins makes navigator.plugins and navigator.mimeTypes report the PDF viewer plugins of a desktop Chrome,
+// because the empty lists of headless Chrome are a common bot-detection signal.
+func WithFakePlugins() PageOption {
+	return func(page *rod.Page) {
+		page.MustEvalOnNewDocument(fakePluginsJS)
+	}
+}
+
+// WithJitteredViewport sets a viewport of a random size within jitterPx pixels of baseW x baseH for each page,
+// so pages of a scrape don't all share the exact same window size.
+func WithJitteredViewport(baseW, baseH, jitterPx int, scale float64) PageOption {
+	return func(page *rod.Page) {
+		page.MustSetViewport(jitter(baseW, jitterPx), jitter(baseH, jitterPx), scale, false)
+	}
+}
+
+// jitter returns a random value within [base-px, base+px], never less than 1.
+func jitter(base, px int) int {
+	if px <= 0 {
+		return base
+	}
+	return max(base+rand.Intn(2*px+1)-px, 1)
+}
+
+// spoofCanvasJS adds a noise derived from the seed to the pixels read back from canvases,
+// so the canvas fingerprint is stable within a page but differs between pages.
+const spoofCanvasJS = `(() => {
+	const seed = %d;
+	const noise = data => {
+		for (let i = 0; i < data.length; i += 4) {
+			let h = Math.imul(seed ^ i, 0x9e3779b1);
+			h = Math.imul(h ^ (h >>> 15), 0x85ebca6b);
+			if (((h ^ (h >>> 13)) & 7) === 0) data[i] ^= 1;
+		}
+	};
+
+	const getImageData = CanvasRenderingContext2D.prototype.getImageData;
+	CanvasRenderingContext2D.prototype.getImageData = function (...args) {
+		const image = getImageData.apply(this, args);
+		noise(image.data);
+		return image;
+	};
+
+	// Draw into a scratch canvas, so the caller's canvas keeps its context, be it WebGL or not created yet
+	const noisyCopy = canvas => {
+		if (!canvas.width || !canvas.height) return canvas;
+		const copy = document.createElement('canvas');
+		copy.width = canvas.width;
+		copy.height = canvas.height;
+		const ctx = copy.getContext('2d');
+		ctx.drawImage(canvas, 0, 0);
+		const image = getImageData.call(ctx, 0, 0, copy.width, copy.height);
+		noise(image.data);
+		ctx.putImageData(image, 0, 0);
+		return copy;
+	};
+
+	const toDataURL = HTMLCanvasElement.prototype.toDataURL;
+	HTMLCanvasElement.prototype.toDataURL = function (...args) {
+		return toDataURL.apply(noisyCopy(this), args);
+	};
+
+	const toBlob = HTMLCanvasElement.prototype.toBlob;
+	HTMLCanvasElement.prototype.toBlob = function (...args) {
+		return toBlob.apply(noisyCopy(this), args);
+	};
+})()`
+
+// WithSpoofCanvas adds a subtle noise to the pixels read back from canvases through
+// toDataURL, toBlob and, for 2D canvases, getImageData, so canvas fingerprinting yields a different result for each page.
+// The noise is seeded once per page, so reading the same drawing twice gives the same result.
+func WithSpoofCanvas() PageOption {
+	return func(page *rod.Page) {
+		page.MustEvalOnNewDocument(fmt.Sprintf(spoofCanvasJS, rand.Int31()))
+	}
+}
@@ -0,0 +1,124 @@
+package browser
+
+import (
+	"github.com/go-rod/rod"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithHardwareProfile(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body>Hardware</body></html>`,
+	})
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage(WithHardwareProfile(8, 8))
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	page.MustNavigate(srv.URL)
+	page.MustWaitLoad()
+
+	assert.Equal(t, 8, page.MustEval(`() => navigator.hardwareConcurrency`).Int())
+	assert.Equal(t, 8.0, page.MustEval(`() => navigator.deviceMemory`).Num())
+}
+
+func TestWithFakePlugins(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body>Plugins</body></html>`,
+	})
+
+	b := newTestBrowser(t)
+
+	page, err := b.GetPage(WithFakePlugins())
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+
+	page.MustNavigate(srv.URL)
+	page.MustWaitLoad()
+
+	assert.Greater(t, page.MustEval(`() => navigator.plugins.length`).Int(), 0)
+	assert.Greater(t, page.MustEval(`() => navigator.mimeTypes.length`).Int(), 0)
+	assert.Equal(t, "PDF Viewer", page.MustEval(`() => navigator.plugins[0].name`).String())
+	assert.True(t, page.MustEval(`() => navigator.plugins instanceof PluginArray`).Bool())
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		v := jitter(1280, 20)
+		assert.GreaterOrEqual(t, v, 1260)
+		assert.LessOrEqual(t, v, 1300)
+	}
+	assert.Equal(t, 1280, jitter(1280, 0))
+	for i := 0; i < 100; i++ {
+		assert.GreaterOrEqual(t, jitter(1, 5), 1, "Sizes should never go below 1")
+	}
+}
+
+func TestWithJitteredViewport(t *testing.T) {
+	b := newTestBrowser(t, WithPoolSize(5))
+
+	widths := make(map[int]bool)
+	for i := 0; i < 5; i++ {
+		page, err := b.GetPage(WithJitteredViewport(1280, 800, 50, 1))
+		assert.NoError(t, err)
+		defer b.PutPage(page)
+
+		size := page.MustEval(`() => [window.innerWidth, window.innerHeight]`).Arr()
+		width, height := size[0].Int(), size[1].Int()
+		assert.GreaterOrEqual(t, width, 1230)
+		assert.LessOrEqual(t, width, 1330)
+		assert.GreaterOrEqual(t, height, 750)
+		assert.LessOrEqual(t, height, 850)
+		widths[width] = true
+	}
+	assert.Greater(t, len(widths), 1, "Viewport sizes should vary between pages")
+}
+
+func TestWithSpoofCanvas(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body><canvas id="canvas" width="200" height="50"></canvas><script>
+			const ctx = document.getElementById('canvas').getContext('2d');
+			ctx.fillStyle = '#f60';
+			ctx.fillRect(0, 0, 200, 50);
+			ctx.fillStyle = '#069';
+			ctx.font = '16px Arial';
+			ctx.fillText('fingerprint', 10, 30);
+		</script></body></html>`,
+	})
+
+	b := newTestBrowser(t, WithPoolSize(2))
+
+	fingerprint := func(page *rod.Page) string {
+		return page.MustEval(`() => document.getElementById('canvas').toDataURL()`).String()
+	}
+
+	page1, err := b.GetPage(WithSpoofCanvas())
+	assert.NoError(t, err)
+	defer b.PutPage(page1)
+	page1.MustNavigate(srv.URL).MustWaitLoad()
+
+	page2, err := b.GetPage(WithSpoofCanvas())
+	assert.NoError(t, err)
+	defer b.PutPage(page2)
+	page2.MustNavigate(srv.URL).MustWaitLoad()
+
+	first := fingerprint(page1)
+	assert.Equal(t, first, fingerprint(page1), "The fingerprint should be stable within a page")
+	assert.NotEqual(t, first, fingerprint(page2), "The fingerprint should differ between pages")
+
+	sum := `() => {
+		const canvas = document.getElementById('canvas');
+		return Array.from(canvas.getContext('2d').getImageData(0, 0, canvas.width, canvas.height).data).reduce((a, b) => a + b, 0);
+	}`
+	assert.Equal(t, page1.MustEval(sum).Int(), page1.MustEval(sum).Int())
+	assert.NotEqual(t, page1.MustEval(sum).Int(), page2.MustEval(sum).Int())
+
+	// Reading a fresh canvas back doesn't lock it to a 2D context.
+	assert.True(t, page1.MustEval(`() => {
+		const canvas = document.createElement('canvas');
+		canvas.toDataURL();
+		return canvas.getContext('bitmaprenderer') !== null;
+	}`).Bool())
+}
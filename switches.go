@@ -0,0 +1,150 @@
+package browser
+
+import (
+	"slices"
+)
+
+// knownSwitches are the Chrome switches commonly passed to automated browsers, which the flags of
+// WithExtraLaunchFlags and WithExtraLaunchSwitches are checked against. Chrome has hundreds of switches
+// and adds new ones with every release, so a switch missing from the list is only reported, never rejected.
+var knownSwitches = []string{
+	"allow-file-access-from-files",
+	"allow-insecure-localhost",
+	"allow-running-insecure-content",
+	"auto-open-devtools-for-tabs",
+	"autoplay-policy",
+	"blink-settings",
+	"disable-accelerated-2d-canvas",
+	"disable-background-networking",
+	"disable-background-timer-throttling",
+	"disable-backgrounding-occluded-windows",
+	"disable-blink-features",
+	"disable-breakpad",
+	"disable-client-side-phishing-detection",
+	"disable-component-extensions-with-background-pages",
+	"disable-component-update",
+	"disable-crash-reporter",
+	"disable-default-apps",
+	"disable-dev-shm-usage",
+	"disable-extensions",
+	"disable-features",
+	"disable-gpu",
+	"disable-hang-monitor",
+	"disable-infobars",
+	"disable-ipc-flooding-protection",
+	"disable-notifications",
+	"disable-popup-blocking",
+	"disable-prompt-on-repost",
+	"disable-renderer-backgrounding",
+	"disable-setuid-sandbox",
+	"disable-site-isolation-trials",
+	"disable-software-rasterizer",
+	"disable-sync",
+	"disable-web-security",
+	"enable-automation",
+	"enable-features",
+	"enable-logging",
+	"enable-unsafe-swiftshader",
+	"enable-webgl",
+	"force-color-profile",
+	"force-device-scale-factor",
+	"full-memory-crash-report",
+	"headless",
+	"hide-scrollbars",
+	"host-resolver-rules",
+	"ignore-certificate-errors",
+	"ignore-certificate-errors-spki-list",
+	"ignore-gpu-blocklist",
+	"ignore-ssl-errors",
+	"incognito",
+	"js-flags",
+	"lang",
+	"load-extension",
+	"log-level",
+	"metrics-recording-only",
+	"mute-audio",
+	"no-default-browser-check",
+	"no-first-run",
+	"no-sandbox",
+	"no-startup-window",
+	"no-zygote",
+	"password-store",
+	"proxy-bypass-list",
+	"proxy-pac-url",
+	"proxy-server",
+	"remote-allow-origins",
+	"remote-debugging-address",
+	"remote-debugging-port",
+	"renderer-process-limit",
+	"safebrowsing-disable-auto-update",
+	"single-process",
+	"start-maximized",
+	"unlimited-storage",
+	"use-angle",
+	"use-fake-device-for-media-stream",
+	"use-fake-ui-for-media-stream",
+	"use-gl",
+	"use-mock-keychain",
+	"user-agent",
+	"user-data-dir",
+	"v",
+	"vmodule",
+	"window-position",
+	"window-size",
+}
+
+// warnUnknownSwitches logs a warning for each extra launch flag that isn't a known Chrome switch,
+// with the closest known switch when it looks like a typo.
+func (b *Browser) warnUnknownSwitches() {
+	names := slices.Clone(b.extraSwitches)
+	for name := range b.extraFlags {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if slices.Contains(knownSwitches, name) {
+			continue
+		}
+		if suggestion := closestSwitch(name); suggestion != "" {
+			b.log().Warn("unknown launch flag", "flag", name, "did_you_mean", suggestion)
+		} else {
+			b.log().Warn("unknown launch flag", "flag", name)
+		}
+	}
+}
+
+// closestSwitch returns the known switch within two edits of the name, or an empty string if there's none.
+func closestSwitch(name string) string {
+	best, bestDistance := "", 3
+	for _, known := range knownSwitches {
+		if d := editDistance(name, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
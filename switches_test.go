@@ -0,0 +1,42 @@
+package browser
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("mute-audio", "mute-audio"))
+	assert.Equal(t, 1, editDistance("mute-audo", "mute-audio"))
+	assert.Equal(t, 2, editDistance("mtue-audio", "mute-audio"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+
+	assert.Equal(t, "disable-gpu", closestSwitch("disable-gpuu"))
+	assert.Equal(t, "", closestSwitch("something-else-entirely"))
+}
+
+func TestWarnUnknownSwitches(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	b := newTestBrowser(t,
+		WithLogger(logger),
+		WithExtraLaunchFlags(map[string]string{"lang": "de-DE", "windw-size": "800,600"}),
+		WithExtraLaunchSwitches("mute-audio", "--disable-extensons"),
+	)
+
+	out := buf.String()
+	assert.Contains(t, out, `level=WARN msg="unknown launch flag" flag=windw-size did_you_mean=window-size`)
+	assert.Contains(t, out, `level=WARN msg="unknown launch flag" flag=disable-extensons did_you_mean=disable-extensions`)
+	assert.NotContains(t, out, "flag=lang")
+	assert.NotContains(t, out, "flag=mute-audio")
+
+	// The browser still launches with the flags.
+	assert.NotNil(t, b.browser)
+	page, err := b.GetPage()
+	assert.NoError(t, err)
+	defer b.PutPage(page)
+	assert.Equal(t, "de-DE", page.MustEval(`() => navigator.language`).String())
+}
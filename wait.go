@@ -0,0 +1,75 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"time"
+)
+
+// imagesLoadedJS reports whether every image of the document is loaded and decoded.
+const imagesLoadedJS = `() => Array.from(document.images).every(img => img.complete && img.naturalWidth > 0)`
+
+// WaitImagesLoaded waits until every image of the page has finished loading, polling until the timeout elapses.
+// Images that fail to load, or lazy images that were never scrolled into view, make the wait time out.
+func (b *Browser) WaitImagesLoaded(page *rod.Page, timeout time.Duration) error {
+	p := page.Timeout(timeout)
+	defer p.CancelTimeout()
+
+	if err := p.Wait(rod.Eval(imagesLoadedJS)); err != nil {
+		return fmt.Errorf("failed to wait for images to load: %w", err)
+	}
+
+	return nil
+}
+
+// WaitFonts waits until the web fonts used by the page have finished loading, or until the timeout elapses,
+// so screenshots don't capture fallback fonts. Fonts that fail to load don't block the wait.
+func (b *Browser) WaitFonts(page *rod.Page, timeout time.Duration) error {
+	p := page.Timeout(timeout)
+	defer p.CancelTimeout()
+
+	if _, err := p.Eval(`() => document.fonts.ready.then(() => document.fonts.status)`); err != nil {
+		return fmt.Errorf("failed to wait for fonts to load: %w", err)
+	}
+
+	return nil
+}
+
+// rateLimitPollInterval is how often WaitOutRateLimit checks whether the indicator is still shown.
+const rateLimitPollInterval = 250 * time.Millisecond
+
+// indicatorShownJS reports whether the element matching the selector exists and is rendered.
+const indicatorShownJS = `sel => {
+	const el = document.querySelector(sel);
+	if (!el) return false;
+	const rect = el.getBoundingClientRect();
+	const style = getComputedStyle(el);
+	return rect.width > 0 && rect.height > 0 && style.visibility !== 'hidden' && style.display !== 'none';
+}`
+
+// WaitOutRateLimit waits while the page shows the element matching indicatorSelector, such as the banner
+// or countdown of a "please wait" page, until it's removed or hidden, or until maxWait elapses.
+// It returns right away if the indicator isn't shown. The page may reload or navigate while waiting,
+// e.g. when the countdown ends, checks that fail meanwhile are retried.
+func (b *Browser) WaitOutRateLimit(page *rod.Page, indicatorSelector string, maxWait time.Duration) error {
+	p := page.Timeout(maxWait)
+	defer p.CancelTimeout()
+
+	ctx := p.GetContext()
+	for {
+		res, err := p.Eval(indicatorShownJS, indicatorSelector)
+		if err == nil && !res.Value.Bool() {
+			return nil
+		}
+		if err == nil {
+			err = errors.New("indicator still shown")
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to wait out rate limit %s within %s: %w", indicatorSelector, maxWait, err)
+		case <-time.After(rateLimitPollInterval):
+		}
+	}
+}
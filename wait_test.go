@@ -0,0 +1,89 @@
+package browser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWaitImagesLoaded(t *testing.T) {
+	images := newImageServer(t)
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow.png":
+			time.Sleep(time.Second)
+			http.Redirect(w, r, images.URL+"/red.png", http.StatusFound)
+		case "/missing.png":
+			http.NotFound(w, r)
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><body>
+				<img src="` + images.URL + `/red.png">
+				<img id="slow">
+				<script>setTimeout(() => document.getElementById('slow').src = '/slow.png', 10)</script>
+			</body></html>`))
+		}
+	}))
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL)
+	assert.NoError(t, b.WaitImagesLoaded(page, 10*time.Second))
+	assert.True(t, page.MustEval(`() => document.getElementById('slow').naturalWidth > 0`).Bool())
+
+	// A broken image never finishes loading.
+	page.MustEval(`() => { const img = document.createElement('img'); img.src = '/missing.png'; document.body.append(img); }`)
+	assert.Error(t, b.WaitImagesLoaded(page, time.Second))
+}
+
+func TestWaitFonts(t *testing.T) {
+	srv := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow.woff2":
+			time.Sleep(time.Second)
+			w.Header().Set("Content-Type", "font/woff2")
+			_, _ = w.Write([]byte("not really a font"))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(`<html><head><style>
+				@font-face { font-family: "Slow"; src: url("/slow.woff2") format("woff2"); }
+				body { font-family: "Slow", sans-serif; }
+			</style></head><body>Hello</body></html>`))
+		}
+	}))
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL)
+	assert.NoError(t, b.WaitFonts(page, 10*time.Second))
+	assert.Equal(t, "loaded", page.MustEval(`() => document.fonts.status`).Str())
+}
+
+func TestWaitOutRateLimit(t *testing.T) {
+	srv := newTestServer(t, testPages{
+		"/": `<html><body>
+			<div id="rate-limited">Rate limited, please wait <span id="countdown">1</span>s</div>
+			<script>setTimeout(() => document.getElementById('rate-limited').style.display = 'none', 1000)</script>
+		</body></html>`,
+		"/stuck": `<html><body><div id="rate-limited">Rate limited</div></body></html>`,
+	})
+
+	b, page := newTestPage(t)
+
+	page.MustNavigate(srv.URL).MustWaitLoad()
+
+	start := time.Now()
+	assert.NoError(t, b.WaitOutRateLimit(page, "#rate-limited", 10*time.Second))
+	assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+	assert.False(t, page.MustElement("#rate-limited").MustVisible())
+
+	// Without an indicator it returns right away.
+	start = time.Now()
+	assert.NoError(t, b.WaitOutRateLimit(page, "#missing", 10*time.Second))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// An indicator that stays up makes the wait time out.
+	page.MustNavigate(srv.URL + "/stuck").MustWaitLoad()
+	assert.Error(t, b.WaitOutRateLimit(page, "#rate-limited", time.Second))
+}